@@ -0,0 +1,40 @@
+package context
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+)
+
+// OnSignal calls fn every time the process receives any of the signals, until
+// ctx is done or the returned stop function is called. Unlike [Shutdown],
+// which handles signals to cancel a context, it is for signals that can be
+// received any number of times, like SIGHUP to reload configuration. Signals
+// are handled one at a time, and a signal received while fn is running is
+// handled after it returns. If no signals are given, fn is never called.
+//
+// When ctx is a [ShutdownContext], fn stops being called once it is done, so
+// a process can handle reload signals until it starts shutting down.
+func OnSignal(ctx context.Context, fn func(os.Signal), signals ...os.Signal) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	if len(signals) == 0 {
+		return cancel
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+	go func() {
+		defer signal.Stop(ch)
+		for {
+			select {
+			case <-ctx.Done():
+				logger.Debug("signal handler stopped")
+				return
+			case sig := <-ch:
+				logger.Debug("signal received", slog.String("signal", sig.String()))
+				fn(sig)
+			}
+		}
+	}()
+	return cancel
+}
@@ -0,0 +1,48 @@
+package context
+
+import (
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"go.chrisrx.dev/x/assert"
+)
+
+func TestOnSignal(t *testing.T) {
+	t.Run("repeated signals", func(t *testing.T) {
+		received := make(chan os.Signal)
+		stop := OnSignal(t.Context(), func(sig os.Signal) {
+			received <- sig
+		}, syscall.SIGUSR2)
+		defer stop()
+
+		for range 3 {
+			go sendSignal(syscall.SIGUSR2)
+			select {
+			case sig := <-received:
+				assert.Equal(t, os.Signal(syscall.SIGUSR2), sig)
+			case <-time.After(5 * time.Second):
+				t.Fatal("timed out waiting for signal")
+			}
+		}
+	})
+
+	t.Run("stopped", func(t *testing.T) {
+		// The signal is also handled here, so that it doesn't terminate the
+		// test process once the handler under test is stopped.
+		stopOther := OnSignal(t.Context(), func(os.Signal) {}, syscall.SIGUSR2)
+		defer stopOther()
+
+		var calls atomic.Int32
+		stop := OnSignal(t.Context(), func(os.Signal) {
+			calls.Add(1)
+		}, syscall.SIGUSR2)
+		stop()
+
+		sendSignal(syscall.SIGUSR2)
+		time.Sleep(50 * time.Millisecond)
+		assert.Equal(t, int32(0), calls.Load())
+	})
+}
@@ -2,6 +2,7 @@ package convert
 
 import (
 	"reflect"
+	"sync/atomic"
 
 	"go.chrisrx.dev/x/internal/reflectx"
 )
@@ -14,6 +15,16 @@ type ConversionFunc[From, To any] func(From, ...Option) (To, error)
 // https://github.com/golang/go/issues/77273
 var conversions = make(map[[2]reflect.Type]ConversionFunc[any, any])
 
+// version is incremented by each call to Register.
+var version atomic.Uint64
+
+// Version returns a number that changes whenever a conversion is registered.
+// Callers caching the results of [Lookup] can compare it to know when their
+// cache is stale.
+func Version() uint64 {
+	return version.Load()
+}
+
 func convert(from, to reflect.Type) [2]reflect.Type {
 	return [2]reflect.Type{
 		reflectx.IndirectType(from),
@@ -40,6 +51,7 @@ func Register[From, To any](fn ConversionFunc[From, To]) {
 		}
 		return reflectx.IndirectFor[To](result)
 	}
+	version.Add(1)
 }
 
 func Lookup(from, to reflect.Type) (ConversionFunc[any, any], bool) {
@@ -0,0 +1,203 @@
+// Command envdoc generates documentation for a config struct parsed by
+// go.chrisrx.dev/x/env. It is meant to be run with go:generate from the
+// package that declares the struct:
+//
+//	//go:generate go run go.chrisrx.dev/x/env/cmd/envdoc -type Config -o CONFIG.md
+//	//go:generate go run go.chrisrx.dev/x/env/cmd/envdoc -type Config -o .env.example
+//	//go:generate go run go.chrisrx.dev/x/env/cmd/envdoc -type Config -o config.schema.json
+//
+// The format is chosen by the extension of the output file, and can be set
+// with -format to one of markdown, dotenv or jsonschema. The struct is loaded
+// by building a program that imports the package, so the type must be
+// exported and the package can't be a main package.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+var (
+	typeName          = flag.String("type", "", "name of the config struct type (required)")
+	output            = flag.String("o", "", "output file, defaults to standard output")
+	format            = flag.String("format", "", "output format: markdown, dotenv or jsonschema")
+	rootPrefix        = flag.String("prefix", "", "root prefix for environment variable names")
+	disableAutoPrefix = flag.Bool("disable-auto-prefix", false, "disable the auto-prefix of nested structs")
+	requireTagged     = flag.Bool("require-tagged", false, "include fields without an env tag")
+)
+
+var writers = map[string]string{
+	"markdown":   "WriteMarkdown",
+	"dotenv":     "WriteDotEnv",
+	"jsonschema": "WriteJSONSchema",
+}
+
+var headers = map[string]string{
+	"markdown": "<!-- Code generated by envdoc. DO NOT EDIT. -->\n\n",
+	"dotenv":   "# Code generated by envdoc. DO NOT EDIT.\n\n",
+}
+
+var program = template.Must(template.New("main").Parse(`package main
+
+import (
+	"log"
+	"os"
+
+	"go.chrisrx.dev/x/env"
+
+	config {{ printf "%q" .Package }}
+)
+
+func main() {
+	opts := []env.ParserOption{
+{{- if .RootPrefix }}
+		env.RootPrefix({{ printf "%q" .RootPrefix }}),
+{{- end }}
+{{- if .DisableAutoPrefix }}
+		env.DisableAutoPrefix(),
+{{- end }}
+{{- if .RequireTagged }}
+		env.RequireTagged(),
+{{- end }}
+	}
+	if err := env.{{ .Writer }}(os.Stdout, new(config.{{ .Type }}), opts...); err != nil {
+		log.Fatal(err)
+	}
+}
+`))
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("envdoc: ")
+	flag.Parse()
+
+	if *typeName == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run() error {
+	f := *format
+	if f == "" {
+		f = formatOf(*output)
+	}
+	writer, ok := writers[f]
+	if !ok {
+		return fmt.Errorf("unknown format: %q", f)
+	}
+
+	pkg, err := goList()
+	if err != nil {
+		return err
+	}
+	if pkg.Name == "main" {
+		return fmt.Errorf("cannot import the config type from a main package")
+	}
+
+	// The program has to be built from within the module of the package so
+	// that it can import it. Rather than writing it to the working directory,
+	// it is written to a temporary directory and added to the package
+	// directory with an overlay, so nothing is left behind if envdoc is
+	// interrupted.
+	dir, err := os.MkdirTemp("", "envdoc")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	overlay, err := writeOverlay(dir, pkg.Dir)
+	if err != nil {
+		return err
+	}
+
+	var src bytes.Buffer
+	if err := program.Execute(&src, map[string]any{
+		"Package":           pkg.ImportPath,
+		"Type":              *typeName,
+		"Writer":            writer,
+		"RootPrefix":        *rootPrefix,
+		"DisableAutoPrefix": *disableAutoPrefix,
+		"RequireTagged":     *requireTagged,
+	}); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), src.Bytes(), 0o644); err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+	out.WriteString(headers[f])
+	cmd := exec.Command("go", "run", "-overlay", overlay, "./"+filepath.Base(dir))
+	cmd.Stdout = &out
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return err
+	}
+
+	if *output == "" {
+		_, err := os.Stdout.Write(out.Bytes())
+		return err
+	}
+	return os.WriteFile(*output, out.Bytes(), 0o644)
+}
+
+// writeOverlay writes an overlay file for the go command that adds the
+// main.go file in dir to a directory of the same name in pkgDir, and returns
+// its path.
+func writeOverlay(dir, pkgDir string) (string, error) {
+	data, err := json.Marshal(map[string]any{
+		"Replace": map[string]string{
+			filepath.Join(pkgDir, filepath.Base(dir), "main.go"): filepath.Join(dir, "main.go"),
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, "overlay.json")
+	return path, os.WriteFile(path, data, 0o644)
+}
+
+// formatOf returns the format for the extension of the output file.
+func formatOf(output string) string {
+	switch {
+	case strings.HasSuffix(output, ".md"):
+		return "markdown"
+	case strings.HasSuffix(output, ".json"):
+		return "jsonschema"
+	default:
+		return "dotenv"
+	}
+}
+
+type goPackage struct {
+	Name       string
+	ImportPath string
+	Dir        string
+}
+
+// goList returns the package in the current directory.
+func goList() (goPackage, error) {
+	out, err := exec.Command("go", "list", "-json=Name,ImportPath,Dir", ".").Output()
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok {
+			return goPackage{}, fmt.Errorf("go list: %s", ee.Stderr)
+		}
+		return goPackage{}, err
+	}
+	var pkg goPackage
+	if err := json.Unmarshal(out, &pkg); err != nil {
+		return goPackage{}, fmt.Errorf("go list: %w", err)
+	}
+	return pkg, nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.chrisrx.dev/x/assert"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+func TestRun(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a program with the go command")
+	}
+	golden, err := filepath.Abs("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir("../../testdata/pg")
+
+	for _, name := range []string{"CONFIG.md", ".env.example", "config.schema.json"} {
+		t.Run(name, func(t *testing.T) {
+			*typeName = "Config"
+			*output = filepath.Join(t.TempDir(), name)
+			*rootPrefix = "PG"
+			if err := run(); err != nil {
+				t.Fatal(err)
+			}
+			data, err := os.ReadFile(*output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(golden, name)
+			if *update {
+				if err := os.WriteFile(path, data, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			expected, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, string(expected), string(data))
+		})
+	}
+
+	// Nothing is written to the directory of the package.
+	entries, err := os.ReadDir(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, len(entries))
+}
@@ -0,0 +1,215 @@
+package env
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"time"
+
+	"go.chrisrx.dev/x/slices"
+	"go.chrisrx.dev/x/strings"
+	"go.chrisrx.dev/x/structs"
+)
+
+// The documentation generated for a config struct covers the same fields, with
+// the same environment variable names, as [Parser.Parse]. The parser options
+// used for parsing must also be passed to the generator for the names to match.
+// The command go.chrisrx.dev/x/env/cmd/envdoc runs the generators with
+// go:generate.
+
+// WriteMarkdown writes a markdown table of the environment variables read for
+// the struct v.
+func WriteMarkdown(w io.Writer, v any, opts ...ParserOption) error {
+	fields, err := docFields(v, opts)
+	if err != nil {
+		return err
+	}
+	var sb strings.Builder
+	sb.WriteString("| Variable | Type | Default | Required | Description |\n")
+	sb.WriteString("| -------- | ---- | ------- | -------- | ----------- |\n")
+	for _, field := range fields {
+		var def string
+		if field.Default() != "" {
+			def = "`" + field.Default() + "`"
+		}
+		var required string
+		if isRequired(field) {
+			required = "yes"
+		}
+		cells := []string{"`" + field.Key() + "`", "`" + docType(field.Type) + "`", def, required, describeField(field)}
+		sb.WriteString("| " + strings.Join(slices.Map(cells, escapeCell), " | ") + " |\n")
+	}
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+func escapeCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+// WriteDotEnv writes an example .env file for the struct v, with a comment
+// describing each variable. Variables are commented out, unless they are
+// required, so that the defaults are used until they are changed.
+func WriteDotEnv(w io.Writer, v any, opts ...ParserOption) error {
+	fields, err := docFields(v, opts)
+	if err != nil {
+		return err
+	}
+	var sb strings.Builder
+	for i, field := range fields {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		fmt.Fprintf(&sb, "# %s (%s)", field.Key(), docType(field.Type))
+		if isRequired(field) {
+			sb.WriteString(", required")
+		}
+		sb.WriteString("\n")
+		if desc := describeField(field); desc != "" {
+			fmt.Fprintf(&sb, "# %s\n", desc)
+		}
+		value := field.Default()
+		if strings.ContainsAny(value, " \t\"'#\\") {
+			value = strconv.Quote(value)
+		}
+		if !isRequired(field) {
+			sb.WriteString("# ")
+		}
+		fmt.Fprintf(&sb, "%s=%s\n", field.Key(), value)
+	}
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+// jsonSchema is the subset of JSON Schema used to describe a config.
+type jsonSchema struct {
+	Schema      string                 `json:"$schema,omitempty"`
+	Type        string                 `json:"type,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Default     any                    `json:"default,omitempty"`
+	WriteOnly   bool                   `json:"writeOnly,omitempty"`
+	Items       *jsonSchema            `json:"items,omitempty"`
+	Properties  map[string]*jsonSchema `json:"properties,omitempty"`
+	Required    []string               `json:"required,omitempty"`
+}
+
+// WriteJSONSchema writes a JSON Schema for the struct v. The schema describes
+// an object with a property for each environment variable, typed by the Go
+// type of the field.
+func WriteJSONSchema(w io.Writer, v any, opts ...ParserOption) error {
+	fields, err := docFields(v, opts)
+	if err != nil {
+		return err
+	}
+	schema := &jsonSchema{
+		Schema:     "https://json-schema.org/draft/2020-12/schema",
+		Type:       "object",
+		Properties: make(map[string]*jsonSchema),
+	}
+	for _, field := range fields {
+		prop := schemaFor(field.Type)
+		prop.Description = describeField(field)
+		prop.WriteOnly = isSecret(field)
+		if field.Default() != "" {
+			prop.Default = schemaDefault(field)
+		}
+		schema.Properties[field.Key()] = prop
+		if isRequired(field) {
+			schema.Required = append(schema.Required, field.Key())
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(schema)
+}
+
+func schemaFor(rt reflect.Type) *jsonSchema {
+	for rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	switch {
+	case rt == reflect.TypeFor[time.Duration](), structs.IsWellKnown(reflect.New(rt).Elem()):
+		return &jsonSchema{Type: "string"}
+	}
+	switch rt.Kind() {
+	case reflect.Bool:
+		return &jsonSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &jsonSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &jsonSchema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &jsonSchema{Type: "array", Items: schemaFor(rt.Elem())}
+	default:
+		return &jsonSchema{Type: "string"}
+	}
+}
+
+// schemaDefault returns the default value of a field as the JSON type of its
+// schema. The default is left as a string if it can't be parsed.
+func schemaDefault(field Field) any {
+	switch schemaFor(field.Type).Type {
+	case "boolean", "integer", "number":
+		rv := reflect.New(field.Type).Elem()
+		if err := structs.ParseField(field.Default(), rv); err == nil {
+			return rv.Interface()
+		}
+	case "array":
+		return strings.Split(field.Default(), field.Separator())
+	}
+	return field.Default()
+}
+
+// docFields returns the fields of v that are documented. Fields with the same
+// environment variable are only included once.
+func docFields(v any, opts []ParserOption) ([]Field, error) {
+	rt := reflect.TypeOf(v)
+	for rt != nil && rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	if rt == nil || rt.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("must provide a struct pointer, received %T", v)
+	}
+	seen := make(map[string]bool)
+	return slices.Filter(NewParser(opts...).Fields(rt), func(field Field) bool {
+		if seen[field.Key()] {
+			return false
+		}
+		seen[field.Key()] = true
+		return true
+	}), nil
+}
+
+// isRequired returns whether a field must be set, which is only when it is
+// required and doesn't have a default.
+func isRequired(field Field) bool {
+	return field.Required && !field.HasDefault()
+}
+
+// docType returns the Go type of a field as shown in documentation.
+func docType(rt reflect.Type) string {
+	return strings.ReplaceAll(rt.String(), "interface {}", "any")
+}
+
+// describeField describes the parts of a field that don't have their own
+// column or key in the generated documentation.
+func describeField(field Field) string {
+	var parts []string
+	if field.DefaultExpr() != "" {
+		parts = append(parts, fmt.Sprintf("Defaults to the result of `%s`.", field.DefaultExpr()))
+	}
+	if field.Validate != "" {
+		parts = append(parts, fmt.Sprintf("Must satisfy `%s`.", field.Validate))
+	}
+	if isSecret(field) {
+		parts = append(parts, "Secret, can be read from a file with file:// or "+field.Key()+"_FILE.")
+	}
+	if field.Reload {
+		parts = append(parts, "Reloadable.")
+	}
+	return strings.Join(parts, " ")
+}
@@ -4,16 +4,19 @@ package env
 
 import (
 	"cmp"
+	"context"
 	"fmt"
 	"os"
 	"reflect"
 	"strconv"
+	"time"
 	"unicode"
 
 	"go.chrisrx.dev/x/convert"
 	"go.chrisrx.dev/x/expr"
 	"go.chrisrx.dev/x/internal/reflectx"
 	"go.chrisrx.dev/x/must"
+	"go.chrisrx.dev/x/slices"
 	"go.chrisrx.dev/x/strings"
 	"go.chrisrx.dev/x/structs"
 )
@@ -65,6 +68,8 @@ func MustParseFor[T any](opts ...ParserOption) T {
 	return v
 }
 
+// Private is a type for fields holding values that are excluded by [Marshal],
+// unless [IncludePrivate] is used.
 type Private any
 
 // Deferred is a special type used in fields to configure calling methods on
@@ -102,14 +107,33 @@ func RootPrefix(prefix string) ParserOption {
 	}
 }
 
+// ExprOptions is an option for [Parser] that sets the options used when
+// evaluating expressions in the `$default` and `validate` tags. This can be
+// used to add custom builtins, or remove builtins that shouldn't be available
+// to configuration:
+//
+//	env.Parse(&cfg, env.ExprOptions(
+//		expr.WithPackage("tenant", map[string]any{"Lookup": tenant.Lookup}),
+//		expr.WithoutBuiltins("getenv", "getwd"),
+//	))
+func ExprOptions(opts ...expr.Option) ParserOption {
+	return func(p *Parser) {
+		p.ExprOptions = append(p.ExprOptions, opts...)
+	}
+}
+
 type setupFunc struct {
 	prefixes []string
 	fn       func() error
 }
 
-func (s setupFunc) IsEnabled() bool {
-	env := strings.ToUpper(strings.Join(append(s.prefixes, "SETUP_DISABLED"), "_"))
-	if v, ok := os.LookupEnv(env); ok {
+// key returns the variable that disables the setup method.
+func (s setupFunc) key() string {
+	return strings.ToUpper(strings.Join(append(slices.Clone(s.prefixes), "SETUP_DISABLED"), "_"))
+}
+
+func (s setupFunc) IsEnabled(p *Parser) bool {
+	if v, ok := p.lookup(s.key()); ok {
 		if must.Get0(strconv.ParseBool(v)) {
 			return false
 		}
@@ -122,8 +146,44 @@ type Parser struct {
 	DisableAutoPrefix bool
 	RootPrefix        string
 	RequireTagged     bool
+	Strict            bool
+	ExprOptions       []expr.Option
+
+	// Sources are the sources values are read from, in order of precedence.
+	// If nil, values are read from environment variables.
+	Sources []Source
+
+	// ReloadSignals and ReloadInterval set when a [Watcher] reloads.
+	ReloadSignals  []os.Signal
+	ReloadInterval time.Duration
 
-	inits []setupFunc
+	// SecretResolvers resolve the values of secrets, keyed by URL scheme.
+	SecretResolvers map[string]SecretResolver
+
+	// IncludeSecrets and IncludePrivate set whether [Marshal] includes the
+	// values of secrets and [Private] fields.
+	IncludeSecrets bool
+	IncludePrivate bool
+
+	// ShowProvenance sets whether [Print] shows where values came from.
+	ShowProvenance bool
+
+	// Context is the context Teardown methods are added to.
+	Context context.Context
+
+	inits      []setupFunc
+	validators []validateFunc
+	teardowns  []setupFunc
+	origins    map[string]Source
+
+	// scopes and known are the prefixes and variables checked by a strict
+	// parser.
+	scopes map[string]struct{}
+	known  map[string]struct{}
+
+	// explained is where the value of each field came from in the last
+	// parse, see [Parser.Explain].
+	explained []explained
 }
 
 // NewParser constructs a new [Parser] using the provided options.
@@ -147,24 +207,40 @@ func (p *Parser) Parse(v any) error {
 		return fmt.Errorf("must provide a struct pointer, received %T", v)
 	}
 
+	if err := p.parseStruct(rv); err != nil {
+		return err
+	}
+	return p.setup()
+}
+
+// parseStruct parses the fields of a struct and calls the Default and
+// Validate methods, but not the Setup methods.
+func (p *Parser) parseStruct(rv reflect.Value) error {
+	p.inits, p.validators, p.teardowns, p.origins = nil, nil, nil, nil
+	p.scopes, p.known, p.explained = nil, nil, nil
+	p.addScope([]string{p.RootPrefix})
+	root := slices.FilterMap([]string{p.RootPrefix}, strings.ToUpper)
+	p.hooks(rv, Field{Field: structs.Field{Name: rv.Type().Name(), Type: rv.Type()}}, root, joinPrefixes(root))
+
 	// The parser root prefix should be added to the initial fields if it is set to
 	// ensure the prefix is set for all child fields.
-	for i := range rv.NumField() {
-		if err := p.parse(rv.Field(i), newField(rv.Type().Field(i), p.RootPrefix)); err != nil {
-			return err
-		}
+	var errs Errors
+	for i, field := range fieldsOf(rv.Type(), p.RootPrefix) {
+		errs = append(errs, p.parse(rv.Field(i), field)...)
 	}
-	for _, init := range p.inits {
-		if init.IsEnabled() {
-			if err := init.fn(); err != nil {
-				return err
-			}
-		}
+	errs = append(errs, p.unknown()...)
+	if len(errs) > 0 {
+		return errs
+	}
+	if errs := p.validate(); len(errs) > 0 {
+		return errs
 	}
 	return nil
 }
 
-func (p *Parser) parse(rv reflect.Value, field Field) error {
+// parse parses a field, returning the errors of every field that failed to
+// parse. Parsing continues after an error so that all errors are returned.
+func (p *Parser) parse(rv reflect.Value, field Field) Errors {
 	if !field.IsExported() && !isDeferred(rv) {
 		return nil
 	}
@@ -181,78 +257,145 @@ func (p *Parser) parse(rv reflect.Value, field Field) error {
 		// If we have a custom parser or a common interface, it is important that
 		// we don't range over it as a struct, so we go ahead and parse it as a
 		// singular value.
-		return p.parseSingular(rv, field)
-	case rv.Kind() == reflect.Struct:
-		// Any prefixes from the parent field should be added to child fields. An
-		// additional prefix will added if the env tag is set, or if auto prefix is
-		// not disabled and the parent field wasn't anonymous (aka embedded).
-		prefixes := field.prefixes
-		switch {
-		case !p.DisableAutoPrefix && !field.Anonymous:
-			prefixes = append(prefixes, cmp.Or(field.Env, strings.ToSnakeCase(field.Name)))
-		default:
-			prefixes = append(prefixes, field.Env)
+		if err := p.parseSingular(rv, field); err != nil {
+			return Errors{err}
 		}
+		return nil
+	case isIndexed(rv.Type()):
+		return p.parseIndexed(rv, field)
+	case rv.Kind() == reflect.Struct:
+		prefixes := p.prefixes(field)
+		p.addScope(prefixes)
 
-		// Check if this struct implements a setup/init method.
-		switch v := reflectx.Interface(rv).(type) {
-		case interface{ Setup() }:
-			p.inits = append(p.inits, setupFunc{
-				prefixes: field.prefixes,
-				fn: func() error {
-					v.Setup()
-					return nil
-				},
-			})
-		case interface{ Setup() error }:
-			p.inits = append(p.inits, setupFunc{
-				prefixes: field.prefixes,
-				fn: func() error {
-					return v.Setup()
-				},
-			})
-		}
+		p.hooks(rv, field, field.prefixes, joinPrefixes(prefixes))
 
-		for i := range rv.NumField() {
-			if err := p.parse(rv.Field(i), newField(rv.Type().Field(i), prefixes...)); err != nil {
-				return err
-			}
+		var errs Errors
+		for i, child := range fieldsOf(rv.Type(), prefixes...) {
+			errs = append(errs, p.parse(rv.Field(i), child)...)
 		}
+		return errs
+	default:
+		if err := p.parseSingular(rv, field); err != nil {
+			return Errors{err}
+		}
+		return nil
+	}
+}
+
+// Fields returns the fields of a struct type that are set by [Parser.Parse],
+// with the same prefixes. Fields of nested structs are included, but not the
+// nested structs themselves.
+func (p *Parser) Fields(rt reflect.Type) []Field {
+	for rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	if rt.Kind() != reflect.Struct {
 		return nil
+	}
+	var fields []Field
+	for _, field := range fieldsOf(rt, p.RootPrefix) {
+		fields = p.fields(fields, field.Type, field, nil)
+	}
+	return fields
+}
+
+func (p *Parser) fields(fields []Field, rt reflect.Type, field Field, seen []reflect.Type) []Field {
+	if !field.IsExported() {
+		return fields
+	}
+	for rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	rv := reflect.New(rt).Elem()
+	switch {
+	case isIndexed(rt):
+		// The fields of elements are included with a placeholder for the
+		// index, e.g. BACKENDS_<N>_HOST.
+		elem := rt.Elem()
+		for elem.Kind() == reflect.Pointer {
+			elem = elem.Elem()
+		}
+		if slices.Contains(seen, elem) {
+			return fields
+		}
+		prefixes := append(p.prefixes(field), placeholder(rt))
+		for _, child := range fieldsOf(elem, prefixes...) {
+			fields = p.fields(fields, child.Type, child, append(seen, elem))
+		}
+		return fields
+	case structs.HasConversion(rv), structs.IsWellKnown(rv), rt.Kind() != reflect.Struct:
+		if !p.RequireTagged && field.Env == "" {
+			return fields
+		}
+		return append(fields, field)
+	case slices.Contains(seen, rt):
+		// Recursive types are only followed once, since parsing would never
+		// finish otherwise.
+		return fields
+	}
+	prefixes := p.prefixes(field)
+	for _, child := range fieldsOf(rt, prefixes...) {
+		fields = p.fields(fields, child.Type, child, append(seen, rt))
+	}
+	return fields
+}
+
+// prefixes returns the prefixes for the child fields of a struct field. Any
+// prefixes from the parent field should be added to child fields. An
+// additional prefix will added if the env tag is set, or if auto prefix is not
+// disabled and the parent field wasn't anonymous (aka embedded).
+func (p *Parser) prefixes(field Field) []string {
+	prefixes := slices.Clone(field.prefixes)
+	switch {
+	case !p.DisableAutoPrefix && !field.Anonymous:
+		return append(prefixes, cmp.Or(field.Env, strings.ToSnakeCase(field.Name)))
 	default:
-		return p.parseSingular(rv, field)
+		return append(prefixes, field.Env)
 	}
 }
 
-func (p *Parser) parseSingular(rv reflect.Value, field Field) error {
+func (p *Parser) parseSingular(rv reflect.Value, field Field) (ferr *FieldError) {
 	if !p.RequireTagged && field.Env == "" {
 		return nil
 	}
+	var s string
+	var ok bool
+	wasZero := rv.IsZero()
+	defer func() {
+		p.explain(rv, field, s, ok, wasZero, ferr)
+	}()
 	if !isValidEnv(field.Env) {
-		return fmt.Errorf("env tag must only contain letters, digits or _: %q", field.Env)
-	}
-	if err := field.set(rv); err != nil {
-		return err
+		return field.error(fmt.Errorf("env tag must only contain letters, digits or _: %q", field.Env))
 	}
-	if field.Validate != "" {
-		result, err := expr.Eval(field.Validate, expr.Env(map[string]reflect.Value{
-			field.Name: rv,
-			"self":     rv,
-		}))
+	p.addKnown(field.Key())
+	s, ok = p.lookup(field.Key())
+	if isSecret(field) {
+		p.addKnown(field.Key() + "_FILE")
+		var err error
+		s, ok, err = p.resolveSecret(field, s, ok)
 		if err != nil {
-			return err
+			return field.error(err)
 		}
-		result = reflectx.Underlying(result)
-		if result.Kind() != reflect.Bool {
-			return fmt.Errorf("expected bool, received %v", result.Type())
-		}
-		if !result.Bool() {
-			return fmt.Errorf(strings.Dedent(`
-					field %v failed validation:
-						condition: %v
-						value: %q
-				`), field.Name, field.Validate, rv.Interface())
+	}
+	if err := field.set(rv, s, ok, p.ExprOptions...); err != nil {
+		return field.error(err)
+	}
+	return p.checkSingular(rv, field)
+}
+
+// checkSingular checks the validate tag of a field that was parsed by
+// [Parser.parseSingular].
+func (p *Parser) checkSingular(rv reflect.Value, field Field) *FieldError {
+	rule, err := field.Check(rv, p.ExprOptions...)
+	if err != nil {
+		return field.error(err)
+	}
+	if rule != "" {
+		value := rv.Interface()
+		if isSecret(field) {
+			value = redacted
 		}
+		return field.error(fmt.Errorf("field %v %w: %v (value: %v)", field.Name, ErrValidation, rule, value))
 	}
 	return nil
 }
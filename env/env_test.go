@@ -6,9 +6,11 @@ import (
 	"crypto/x509"
 	"encoding"
 	"encoding/pem"
+	"fmt"
 	"log/slog"
 	"net"
 	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -19,6 +21,7 @@ import (
 	"go.chrisrx.dev/x/env/testdata/pg"
 	"go.chrisrx.dev/x/env/testdata/pubsub"
 	"go.chrisrx.dev/x/env/testdata/spanner"
+	"go.chrisrx.dev/x/expr"
 	"go.chrisrx.dev/x/must"
 	"go.chrisrx.dev/x/ptr"
 )
@@ -374,6 +377,40 @@ func TestParse(t *testing.T) {
 		assert.Equal(t, "1", opts.Result2)
 	})
 
+	t.Run("expression scripts", func(t *testing.T) {
+		assert.WithEnviron(t, map[string]string{
+			"PGHOST": "db.internal",
+		}, func() {
+			opts := env.MustParseFor[struct {
+				DSN string `env:"DSN" $default:"host := coalesce(getenv('PGHOST'), 'localhost'); port := coalesce(getenv('PGPORT'), '5432'); sprintf('postgres://%s:%s', host, port)"`
+			}]()
+			assert.Equal(t, "postgres://db.internal:5432", opts.DSN)
+		})
+
+		opts := env.MustParseFor[struct {
+			Sep string `env:"SEP" $default:"'a;b:=c'"`
+		}]()
+		assert.Equal(t, "a;b:=c", opts.Sep)
+	})
+
+	t.Run("expression options", func(t *testing.T) {
+		type s struct {
+			Tenant string `env:"TENANT" $default:"tenant.lookup(1)" validate:"tenant.valid(self)"`
+		}
+		opts, err := env.ParseFor[s](env.ExprOptions(
+			expr.WithPackage("tenant", map[string]any{
+				"Lookup": func(id int) string { return fmt.Sprintf("tenant-%d", id) },
+				"Valid":  func(s string) bool { return strings.HasPrefix(s, "tenant-") },
+			}),
+		))
+		assert.NoError(t, err)
+		assert.Equal(t, "tenant-1", opts.Tenant)
+
+		assert.Error(t, "undefined: getenv", must.Get1(env.ParseFor[struct {
+			Home string `env:"HOME_DIR" $default:"getenv('HOME')"`
+		}](env.ExprOptions(expr.WithoutBuiltins("getenv")))))
+	})
+
 	t.Run("validate", func(t *testing.T) {
 		assert.WithEnviron(t, map[string]string{
 			"UUID": "7b2b2c53-0d22-44af-8ac5-e080434352b2",
@@ -467,3 +504,37 @@ type SetupTest struct {
 func (s *SetupTest) Setup() {
 	s.DefaultValue = "is set"
 }
+
+func BenchmarkParse(b *testing.B) {
+	type Config struct {
+		Addr     string            `env:"ADDR" default:":8080"`
+		Timeout  time.Duration     `env:"TIMEOUT" default:"30s"`
+		Hosts    []string          `env:"HOSTS"`
+		Labels   map[string]string `env:"LABELS"`
+		IP       net.IP            `env:"IP"`
+		Debug    bool              `env:"DEBUG"`
+		Database pg.Config         `env:"DB"`
+		Backends []struct {
+			Host string `env:"HOST"`
+			Port int    `env:"PORT" default:"80"`
+		} `env:"BACKENDS"`
+	}
+	src := env.Map(map[string]string{
+		"APP_ADDR":            ":9090",
+		"APP_HOSTS":           "a,b,c",
+		"APP_LABELS":          "a=1,b=2",
+		"APP_IP":              "127.0.0.1",
+		"APP_DEBUG":           "true",
+		"APP_DB_HOST":         "db.local",
+		"APP_DB_PORT":         "5433",
+		"APP_BACKENDS_0_HOST": "a.local",
+		"APP_BACKENDS_1_HOST": "b.local",
+	})
+	p := env.NewParser(env.RootPrefix("APP"), env.WithSources(src))
+	for b.Loop() {
+		var cfg Config
+		if err := p.Parse(&cfg); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
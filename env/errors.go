@@ -0,0 +1,73 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+
+	"go.chrisrx.dev/x/slices"
+	"go.chrisrx.dev/x/strings"
+	"go.chrisrx.dev/x/structs"
+)
+
+var (
+	// ErrRequired is returned for a required field that isn't set and has no
+	// default value.
+	ErrRequired = errors.New("required field not set")
+
+	// ErrParse is returned when the value of a field cannot be parsed.
+	ErrParse = errors.New("cannot parse value")
+
+	// ErrValidation is returned when the value of a field fails the
+	// `validate` tag of the field, or a Validate method. It is the same as
+	// [structs.ErrValidation].
+	ErrValidation = structs.ErrValidation
+)
+
+// FieldError is an error for a single field. It wraps [ErrRequired],
+// [ErrParse] or [ErrValidation] when the error is for one of those reasons.
+type FieldError struct {
+	// Key is the environment variable name of the field. For errors returned
+	// by Validate methods, it is the prefix of the struct.
+	Key string
+
+	// Field is the name of the struct field.
+	Field string
+
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	if e.Key == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s: %v", e.Key, e.Err)
+}
+
+func (e *FieldError) Unwrap() error { return e.Err }
+
+// Errors is returned by [Parser.Parse] when parsing one or more fields fails.
+// Every field is parsed, so it lists all failing fields rather than only the
+// first one. It can be checked with [errors.Is] and [errors.As], e.g. to find
+// out if any required field isn't set:
+//
+//	if errors.Is(err, env.ErrRequired) {
+//		...
+//	}
+type Errors []*FieldError
+
+func (e Errors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "env: %d errors:", len(e))
+	for _, err := range e {
+		sb.WriteString("\n\t")
+		sb.WriteString(err.Error())
+	}
+	return sb.String()
+}
+
+func (e Errors) Unwrap() []error {
+	return slices.Map(e, func(err *FieldError) error { return err })
+}
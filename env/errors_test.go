@@ -0,0 +1,63 @@
+package env_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"go.chrisrx.dev/x/assert"
+	"go.chrisrx.dev/x/env"
+)
+
+func TestErrors(t *testing.T) {
+	type config struct {
+		Host    string `env:"HOST" required:"true"`
+		Port    int    `env:"PORT"`
+		Workers int    `env:"WORKERS" validate:"Workers > 0"`
+		Debug   bool   `env:"DEBUG"`
+	}
+
+	t.Run("all fields", func(t *testing.T) {
+		_, err := env.ParseFor[config](env.WithSources(env.Map(map[string]string{
+			"PORT":    "http",
+			"WORKERS": "0",
+			"DEBUG":   "true",
+		})))
+		var errs env.Errors
+		assert.Equal(t, true, errors.As(err, &errs))
+		assert.Equal(t, 3, len(errs))
+		assert.Equal(t, "HOST", errs[0].Key)
+		assert.Equal(t, "Host", errs[0].Field)
+		assert.Equal(t, "PORT", errs[1].Key)
+		assert.Equal(t, "WORKERS", errs[2].Key)
+
+		assert.Error(t, env.ErrRequired, err)
+		assert.Error(t, env.ErrParse, err)
+		assert.Error(t, env.ErrValidation, err)
+		assert.Error(t, env.ErrParse, errs[1])
+		assert.Equal(t, false, errors.Is(errs[1], env.ErrRequired))
+
+		var numErr *strconv.NumError
+		assert.Equal(t, true, errors.As(err, &numErr))
+		assert.Equal(t, "http", numErr.Num)
+
+		assert.Equal(t, `env: 3 errors:
+	HOST: required field not set
+	PORT: cannot parse value: strconv.ParseInt: parsing "http": invalid syntax
+	WORKERS: field Workers failed validation: Workers > 0 (value: 0)`, err.Error())
+	})
+
+	t.Run("single error", func(t *testing.T) {
+		_, err := env.ParseFor[config](env.WithSources(env.Map(map[string]string{
+			"HOST":    "localhost",
+			"WORKERS": "1",
+		})))
+		assert.NoError(t, err)
+
+		_, err = env.ParseFor[config](env.WithSources(env.Map(map[string]string{
+			"WORKERS": "1",
+		})))
+		assert.Error(t, env.ErrRequired, err)
+		assert.Equal(t, "HOST: required field not set", err.Error())
+	})
+}
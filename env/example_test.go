@@ -68,6 +68,8 @@ func ExamplePrint() {
 	// Addr{
 	//   env=ADDR
 	//   default=:8080
+	//   validate=split_addr(self).port > 1024
+	//   see=https://github.com/ChrisRx/exp/blob/main/expr/BUILTINS.md#split_addr
 	//   value=:8080
 	// }
 	// Dir{
@@ -89,3 +91,186 @@ func ExamplePrint() {
 	//   value=1048576
 	// }
 }
+
+func ExamplePrint_secrets() {
+	env.Print(env.MustParseFor[struct {
+		User     string             `env:"USER"`
+		Password string             `env:"PASSWORD" secret:"true"`
+		Token    env.Secret[string] `env:"TOKEN"`
+	}](env.WithSources(env.Map(map[string]string{
+		"USER":     "admin",
+		"PASSWORD": "hunter2",
+		"TOKEN":    "abc123",
+	}))))
+
+	// Output:
+	// User{
+	//   env=USER
+	//   value=admin
+	// }
+	// Password{
+	//   env=PASSWORD
+	//   secret=true
+	//   value=REDACTED
+	// }
+	// Token{
+	//   env=TOKEN
+	//   secret=true
+	//   value=REDACTED
+	// }
+}
+
+func ExamplePrint_provenance() {
+	p := env.NewParser(env.ShowProvenance(), env.WithSources(env.Map(map[string]string{
+		"ADDR":  ":9090",
+		"TOKEN": "abc123",
+	})))
+	var cfg struct {
+		Addr    string             `env:"ADDR" default:":8080"`
+		Workers int                `env:"WORKERS" $default:"2 * 4"`
+		Token   env.Secret[string] `env:"TOKEN"`
+	}
+	if err := p.Parse(&cfg); err != nil {
+		log.Fatal(err)
+	}
+
+	p.Print(cfg)
+
+	// Output:
+	// Addr{
+	//   env=ADDR
+	//   default=:8080
+	//   origin=source
+	//   source=map
+	//   raw=:9090
+	//   value=:9090
+	// }
+	// Workers{
+	//   env=WORKERS
+	//   origin=expression
+	//   raw=2 * 4
+	//   value=8
+	// }
+	// Token{
+	//   env=TOKEN
+	//   origin=source
+	//   source=map
+	//   raw=REDACTED
+	//   secret=true
+	//   value=REDACTED
+	// }
+}
+
+type docsConfig struct {
+	Addr     string             `env:"ADDR" default:":8080" validate:"split_addr(self).port > 1024"`
+	Dir      string             `env:"DIR" $default:"tempdir()"`
+	LogLevel string             `env:"LOG_LEVEL" default:"info" reload:"true"`
+	Password env.Secret[string] `env:"PASSWORD" required:"true"`
+	Database struct {
+		Hosts   []string      `env:"HOSTS" default:"localhost"`
+		Timeout time.Duration `env:"TIMEOUT" default:"5s"`
+		Debug   bool          `env:"DEBUG" default:"false"`
+	}
+}
+
+func ExampleWriteMarkdown() {
+	if err := env.WriteMarkdown(os.Stdout, docsConfig{}, env.RootPrefix("MYSERVICE")); err != nil {
+		log.Fatal(err)
+	}
+
+	// Output:
+	// | Variable | Type | Default | Required | Description |
+	// | -------- | ---- | ------- | -------- | ----------- |
+	// | `MYSERVICE_ADDR` | `string` | `:8080` |  | Must satisfy `split_addr(self).port > 1024`. |
+	// | `MYSERVICE_DIR` | `string` |  |  | Defaults to the result of `tempdir()`. |
+	// | `MYSERVICE_LOG_LEVEL` | `string` | `info` |  | Reloadable. |
+	// | `MYSERVICE_PASSWORD` | `env.Secret[string]` |  | yes | Secret, can be read from a file with file:// or MYSERVICE_PASSWORD_FILE. |
+	// | `MYSERVICE_DATABASE_HOSTS` | `[]string` | `localhost` |  |  |
+	// | `MYSERVICE_DATABASE_TIMEOUT` | `time.Duration` | `5s` |  |  |
+	// | `MYSERVICE_DATABASE_DEBUG` | `bool` | `false` |  |  |
+}
+
+func ExampleWriteDotEnv() {
+	if err := env.WriteDotEnv(os.Stdout, docsConfig{}, env.RootPrefix("MYSERVICE")); err != nil {
+		log.Fatal(err)
+	}
+
+	// Output:
+	// # MYSERVICE_ADDR (string)
+	// # Must satisfy `split_addr(self).port > 1024`.
+	// # MYSERVICE_ADDR=:8080
+	//
+	// # MYSERVICE_DIR (string)
+	// # Defaults to the result of `tempdir()`.
+	// # MYSERVICE_DIR=
+	//
+	// # MYSERVICE_LOG_LEVEL (string)
+	// # Reloadable.
+	// # MYSERVICE_LOG_LEVEL=info
+	//
+	// # MYSERVICE_PASSWORD (env.Secret[string]), required
+	// # Secret, can be read from a file with file:// or MYSERVICE_PASSWORD_FILE.
+	// MYSERVICE_PASSWORD=
+	//
+	// # MYSERVICE_DATABASE_HOSTS ([]string)
+	// # MYSERVICE_DATABASE_HOSTS=localhost
+	//
+	// # MYSERVICE_DATABASE_TIMEOUT (time.Duration)
+	// # MYSERVICE_DATABASE_TIMEOUT=5s
+	//
+	// # MYSERVICE_DATABASE_DEBUG (bool)
+	// # MYSERVICE_DATABASE_DEBUG=false
+}
+
+func ExampleWriteJSONSchema() {
+	if err := env.WriteJSONSchema(os.Stdout, docsConfig{}, env.RootPrefix("MYSERVICE")); err != nil {
+		log.Fatal(err)
+	}
+
+	// Output:
+	// {
+	//   "$schema": "https://json-schema.org/draft/2020-12/schema",
+	//   "type": "object",
+	//   "properties": {
+	//     "MYSERVICE_ADDR": {
+	//       "type": "string",
+	//       "description": "Must satisfy `split_addr(self).port > 1024`.",
+	//       "default": ":8080"
+	//     },
+	//     "MYSERVICE_DATABASE_DEBUG": {
+	//       "type": "boolean",
+	//       "default": false
+	//     },
+	//     "MYSERVICE_DATABASE_HOSTS": {
+	//       "type": "array",
+	//       "default": [
+	//         "localhost"
+	//       ],
+	//       "items": {
+	//         "type": "string"
+	//       }
+	//     },
+	//     "MYSERVICE_DATABASE_TIMEOUT": {
+	//       "type": "string",
+	//       "default": "5s"
+	//     },
+	//     "MYSERVICE_DIR": {
+	//       "type": "string",
+	//       "description": "Defaults to the result of `tempdir()`."
+	//     },
+	//     "MYSERVICE_LOG_LEVEL": {
+	//       "type": "string",
+	//       "description": "Reloadable.",
+	//       "default": "info"
+	//     },
+	//     "MYSERVICE_PASSWORD": {
+	//       "type": "string",
+	//       "description": "Secret, can be read from a file with file:// or MYSERVICE_PASSWORD_FILE.",
+	//       "writeOnly": true
+	//     }
+	//   },
+	//   "required": [
+	//     "MYSERVICE_PASSWORD"
+	//   ]
+	// }
+}
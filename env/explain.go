@@ -0,0 +1,157 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+
+	"go.chrisrx.dev/x/internal/reflectx"
+	"go.chrisrx.dev/x/structs"
+)
+
+// Origin is where the value of a field came from.
+type Origin string
+
+const (
+	// OriginSource is a value read from a [Source], e.g. an environment
+	// variable.
+	OriginSource Origin = "source"
+
+	// OriginDefault is a value from the `default` tag.
+	OriginDefault Origin = "default"
+
+	// OriginExpr is a value from evaluating the `$default` tag.
+	OriginExpr Origin = "expression"
+
+	// OriginMethod is a value set by a Default or Setup method, or changed
+	// after parsing.
+	OriginMethod Origin = "method"
+
+	// OriginZero is a field that wasn't set, so it has the zero value.
+	OriginZero Origin = "zero"
+)
+
+// Explanation describes where the value of a field came from.
+type Explanation struct {
+	// Key is the environment variable of the field.
+	Key string
+
+	// Field is the name of the struct field.
+	Field string
+
+	Origin Origin
+
+	// Source is the source the value was read from, if the origin is
+	// [OriginSource].
+	Source Source
+
+	// Raw is the string the value was parsed from, which is the value of the
+	// variable, the `default` tag or the `$default` expression.
+	Raw string
+
+	// Value is the final value of the field, formatted the same as by
+	// [Marshal].
+	Value string
+
+	Secret bool
+
+	// Err is the error of the field if it failed to parse or validate, in
+	// which case Value is what the field was left with.
+	Err error
+}
+
+// explained is an explanation recorded while parsing.
+type explained struct {
+	Explanation
+
+	// parsed is a copy of the value the field was parsed into, which is
+	// compared with the final value to find changes made after parsing. The
+	// tag of the field is kept to format it.
+	parsed reflect.Value
+	tag    reflect.StructTag
+}
+
+// ShowProvenance is an option for [Parser.Print] that shows where the value
+// of each field came from, as returned by [Parser.Explain].
+func ShowProvenance() ParserOption {
+	return func(p *Parser) {
+		p.ShowProvenance = true
+	}
+}
+
+// Explain returns where the value of each field of the struct v came from in
+// the last call to [Parser.Parse], in the order the fields were parsed. It is
+// recorded while parsing, so sources and secrets aren't read again. The
+// values of v are compared with the values the fields were parsed into, and
+// the values that differ, e.g. because they were changed by a Setup method,
+// are attributed to [OriginMethod]. The values of secrets are REDACTED.
+//
+// Fields that failed to parse or validate are included with the error in
+// [Explanation.Err], so a failed parse can be explained as well.
+func (p *Parser) Explain(v any) ([]Explanation, error) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("must provide a struct, received %T", v)
+	}
+
+	// A copy of the parser is used so that the options of p aren't changed.
+	q := *p
+	q.IncludeSecrets, q.IncludePrivate = true, true
+	final := make(map[string]string)
+	q.marshalStruct(final, reflectx.MakeAddressable(rv).Elem())
+
+	explanations := make([]Explanation, len(p.explained))
+	for i, e := range p.explained {
+		parsed, set, err := format(e.parsed, Field{Field: structs.Field{Tag: e.tag}})
+		if err != nil {
+			parsed = fmt.Sprint(e.parsed.Interface())
+		}
+		e.Value = parsed
+		if value, ok := final[e.Key]; err == nil && e.Err == nil && (value != parsed || ok != set) {
+			e.Origin, e.Source, e.Value = OriginMethod, nil, value
+		}
+		if e.Secret {
+			e.Raw, e.Value = redact(e.Raw), redact(e.Value)
+		}
+		explanations[i] = e.Explanation
+	}
+	return explanations, nil
+}
+
+// explain records where the value of a field came from, after it was set.
+// The value was already set before parsing if it isn't set by the raw value
+// or the default.
+func (p *Parser) explain(rv reflect.Value, field Field, s string, ok, wasZero bool, ferr *FieldError) {
+	e := Explanation{
+		Key:    field.Key(),
+		Field:  field.Name,
+		Origin: OriginZero,
+		Secret: isSecret(field),
+	}
+	if ferr != nil {
+		e.Err = ferr
+	}
+	switch {
+	case ok:
+		e.Origin, e.Raw = OriginSource, s
+		e.Source, _ = p.Source(field.Key())
+		if e.Source == nil && e.Secret {
+			e.Source, _ = p.Source(field.Key() + "_FILE")
+		}
+	case !wasZero:
+		e.Origin = OriginMethod
+	case field.DefaultExpr() != "":
+		e.Origin, e.Raw = OriginExpr, field.DefaultExpr()
+	case field.Default() != "":
+		e.Origin, e.Raw = OriginDefault, field.Default()
+	}
+	parsed := reflect.New(rv.Type()).Elem()
+	parsed.Set(rv)
+	p.explained = append(p.explained, explained{Explanation: e, parsed: parsed, tag: field.Tag})
+}
+
+func redact(s string) string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
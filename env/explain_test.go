@@ -0,0 +1,111 @@
+package env_test
+
+import (
+	"testing"
+
+	"go.chrisrx.dev/x/assert"
+	"go.chrisrx.dev/x/env"
+)
+
+type explainConfig struct {
+	Addr     string             `env:"ADDR" default:":8080"`
+	Workers  int                `env:"WORKERS" $default:"2 * 4"`
+	Region   string             `env:"REGION"`
+	Debug    bool               `env:"DEBUG"`
+	Token    env.Secret[string] `env:"TOKEN"`
+	Database struct {
+		Host string `env:"HOST"`
+	}
+}
+
+func (c *explainConfig) Default() { c.Region = "us-east-1" }
+func (c *explainConfig) Setup()   { c.Database.Host = "db." + c.Region }
+
+func TestExplain(t *testing.T) {
+	src := env.Map(map[string]string{
+		"APP_DEBUG":         "true",
+		"APP_TOKEN":         "abc123",
+		"APP_DATABASE_HOST": "localhost",
+	})
+	p := env.NewParser(env.RootPrefix("APP"), env.WithSources(src))
+
+	var cfg explainConfig
+	assert.NoError(t, p.Parse(&cfg))
+	assert.Equal(t, "db.us-east-1", cfg.Database.Host)
+
+	explanations, err := p.Explain(&cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, []env.Explanation{
+		{Key: "APP_ADDR", Field: "Addr", Origin: env.OriginDefault, Raw: ":8080", Value: ":8080"},
+		{Key: "APP_WORKERS", Field: "Workers", Origin: env.OriginExpr, Raw: "2 * 4", Value: "8"},
+		{Key: "APP_REGION", Field: "Region", Origin: env.OriginMethod, Value: "us-east-1"},
+		{Key: "APP_DEBUG", Field: "Debug", Origin: env.OriginSource, Source: src, Raw: "true", Value: "true"},
+		{Key: "APP_TOKEN", Field: "Token", Origin: env.OriginSource, Source: src, Raw: "REDACTED", Value: "REDACTED", Secret: true},
+		{Key: "APP_DATABASE_HOST", Field: "Host", Origin: env.OriginMethod, Raw: "localhost", Value: "db.us-east-1"},
+	}, explanations)
+
+	// Explaining doesn't change the struct or the last parse.
+	assert.Equal(t, "db.us-east-1", cfg.Database.Host)
+	got, ok := p.Source("APP_DATABASE_HOST")
+	assert.Equal(t, true, ok)
+	assert.Equal(t, src, got)
+
+	t.Run("zero", func(t *testing.T) {
+		p := env.NewParser(env.WithSources(env.Map(nil)))
+		var cfg struct {
+			Name string `env:"NAME"`
+		}
+		assert.NoError(t, p.Parse(&cfg))
+		explanations, err := p.Explain(cfg)
+		assert.NoError(t, err)
+		assert.Equal(t, []env.Explanation{
+			{Key: "NAME", Field: "Name", Origin: env.OriginZero},
+		}, explanations)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		src := env.Map(map[string]string{
+			"PORT": "http",
+			"MODE": "fast",
+		})
+		p := env.NewParser(env.WithSources(src))
+		var cfg struct {
+			Name string `env:"NAME" default:"x"`
+			Port int    `env:"PORT"`
+			Mode string `env:"MODE" validate:"oneof=dev prod"`
+		}
+		assert.Error(t, env.ErrParse, p.Parse(&cfg))
+
+		// Fields that failed are explained with their error.
+		explanations, err := p.Explain(cfg)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, len(explanations))
+		assert.Equal(t, env.Explanation{Key: "NAME", Field: "Name", Origin: env.OriginDefault, Raw: "x", Value: "x"}, explanations[0])
+		port := explanations[1]
+		assert.Equal(t, env.OriginSource, port.Origin)
+		assert.Equal(t, "http", port.Raw)
+		assert.Error(t, env.ErrParse, port.Err)
+		mode := explanations[2]
+		assert.Equal(t, "fast", mode.Value)
+		assert.Error(t, env.ErrValidation, mode.Err)
+	})
+
+	t.Run("secrets aren't resolved again", func(t *testing.T) {
+		var calls int
+		p := env.NewParser(
+			env.WithSources(env.Map(map[string]string{"TOKEN": "vault://token"})),
+			env.WithSecretResolver("vault", env.SecretResolverFunc(func(string) (string, error) {
+				calls++
+				return "abc123", nil
+			})),
+		)
+		var cfg struct {
+			Token env.Secret[string] `env:"TOKEN"`
+		}
+		assert.NoError(t, p.Parse(&cfg))
+		explanations, err := p.Explain(cfg)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, "REDACTED", explanations[0].Value)
+	})
+}
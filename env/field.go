@@ -2,10 +2,11 @@ package env
 
 import (
 	"fmt"
-	"os"
 	"reflect"
 	"strconv"
 
+	"go.chrisrx.dev/x/convert"
+	"go.chrisrx.dev/x/expr"
 	"go.chrisrx.dev/x/must"
 	"go.chrisrx.dev/x/slices"
 	"go.chrisrx.dev/x/strings"
@@ -20,6 +21,8 @@ type Field struct {
 	Env      string
 	Validate string
 	Required bool
+	Reload   bool
+	Secret   bool
 
 	prefixes []string
 }
@@ -30,27 +33,61 @@ func newField(st reflect.StructField, prefixes ...string) Field {
 		Env:      st.Tag.Get("env"),
 		Validate: st.Tag.Get("validate"),
 		Required: must.Get0(strconv.ParseBool(st.Tag.Get("required"))),
+		Reload:   must.Get0(strconv.ParseBool(st.Tag.Get("reload"))),
+		Secret:   must.Get0(strconv.ParseBool(st.Tag.Get("secret"))),
 		prefixes: slices.FilterMap(prefixes, strings.ToUpper),
 	}
 }
 
+// fieldsKey is the key of the fields of a struct type cached by
+// [structs.FieldsOf].
+type fieldsKey struct{}
+
+// fieldsOf returns the fields of a struct type with the provided prefixes.
+// The tags of a type are only parsed once and cached with its structs plan,
+// since they are read for every struct that is parsed.
+func fieldsOf(rt reflect.Type, prefixes ...string) []Field {
+	fields := slices.Clone(structs.FieldsOf(rt, fieldsKey{}, func(f structs.Field) Field {
+		return newField(reflect.StructField(f))
+	}))
+
+	// The prefixes are shared by the fields, so they are clipped to ensure
+	// appending to them never changes another field.
+	prefixes = slices.Clip(slices.FilterMap(prefixes, strings.ToUpper))
+	for i := range fields {
+		fields[i].prefixes = prefixes
+	}
+	return fields
+}
+
 // Key returns the environment variable name in screaming snake case. It
 // includes any prefixes defined for this field.
 func (f Field) Key() string {
 	return strings.Join(append(f.prefixes, f.Env), "_")
 }
 
-func (f Field) set(rv reflect.Value) error {
-	s, ok := os.LookupEnv(f.Key())
+// set sets the field to the value read for it, or to its default value if
+// no value was read. The value of a [Secret] is set directly, so that it is
+// parsed with the tags of the field.
+func (f Field) set(rv reflect.Value, s string, ok bool, exprOpts ...expr.Option) error {
+	rv = secretElem(rv)
 	if !ok {
-		ok, err := f.SetDefault(rv)
+		ok, err := f.SetDefault(rv, exprOpts...)
 		if err != nil {
-			return err
+			return fmt.Errorf("invalid default: %w", err)
 		}
 		if !ok && f.Required {
-			return fmt.Errorf("required field not set: %v", f.Key())
+			return ErrRequired
 		}
 		return nil
 	}
-	return structs.ParseField(s, rv)
+	if err := structs.ParseField(s, rv, convert.Layout(f.Layout()), convert.Separator(f.Separator())); err != nil {
+		return fmt.Errorf("%w: %w", ErrParse, err)
+	}
+	return nil
+}
+
+// error returns a [*FieldError] for the field.
+func (f Field) error(err error) *FieldError {
+	return &FieldError{Key: f.Key(), Field: f.Name, Err: err}
 }
@@ -0,0 +1,133 @@
+package env
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	xcontext "go.chrisrx.dev/x/context"
+	"go.chrisrx.dev/x/internal/reflectx"
+	"go.chrisrx.dev/x/structs"
+)
+
+// Structs can implement methods that are called while parsing, which are
+// found on the parsed struct and on all of its nested structs:
+//
+//   - Default(), see [structs.DefaultsFunc], is called before the fields of
+//     the struct are parsed, so that it can set default values. Fields it
+//     sets are still overwritten by values that are set, but take precedence
+//     over the default tags.
+//   - Validate() error is called after every field has been parsed, to check
+//     invariants between fields. An error is returned as a field error wrapping
+//     [ErrValidation].
+//   - Setup() or Setup() error is called after validation.
+//   - Teardown() is added to the [context.ShutdownContext] set with
+//     [WithContext], to be called when it is closed.
+//
+// Each kind of method is called parent before child, in the order the fields
+// are declared. Setup and Teardown methods are disabled by setting the
+// SETUP_DISABLED variable with the prefixes of the parent struct, e.g.
+// DATABASE_SETUP_DISABLED for a struct in the Database field of the struct
+// being parsed. Methods promoted from embedded structs are only called for
+// the embedded struct.
+
+// WithContext is an option for [Parser] that sets the context Teardown methods
+// are added to. It must contain a [context.ShutdownContext], such as the one
+// returned by [context.Shutdown], otherwise Teardown methods aren't called.
+func WithContext(ctx context.Context) ParserOption {
+	return func(p *Parser) {
+		p.Context = ctx
+	}
+}
+
+// validateFunc is a Validate method of a struct.
+type validateFunc struct {
+	field Field
+	key   string
+	fn    func() error
+}
+
+// hooks calls the Default method of a struct and adds the other lifecycle
+// methods to be called after parsing. The prefixes are those of the parent
+// struct, and key is the prefix of the struct itself.
+func (p *Parser) hooks(rv reflect.Value, field Field, prefixes []string, key string) {
+	v := reflectx.Interface(rv)
+	rt := rv.Type()
+	if v, ok := v.(structs.DefaultsFunc); ok && !reflectx.IsPromoted(rt, "Default") {
+		v.Default()
+	}
+	p.addValidator(rv, field, key)
+	if !reflectx.IsPromoted(rt, "Setup") {
+		switch v := v.(type) {
+		case interface{ Setup() }:
+			p.inits = append(p.inits, setupFunc{
+				prefixes: prefixes,
+				fn: func() error {
+					v.Setup()
+					return nil
+				},
+			})
+		case interface{ Setup() error }:
+			p.inits = append(p.inits, setupFunc{
+				prefixes: prefixes,
+				fn:       v.Setup,
+			})
+		}
+	}
+	if v, ok := v.(interface{ Teardown() }); ok && !reflectx.IsPromoted(rt, "Teardown") {
+		p.teardowns = append(p.teardowns, setupFunc{
+			prefixes: prefixes,
+			fn: func() error {
+				v.Teardown()
+				return nil
+			},
+		})
+	}
+}
+
+// addValidator adds the Validate method of a struct, if it has one.
+func (p *Parser) addValidator(rv reflect.Value, field Field, key string) {
+	v, ok := reflectx.Interface(rv).(interface{ Validate() error })
+	if ok && !reflectx.IsPromoted(rv.Type(), "Validate") {
+		p.validators = append(p.validators, validateFunc{
+			field: field,
+			key:   key,
+			fn:    v.Validate,
+		})
+	}
+}
+
+// validate calls the Validate methods.
+func (p *Parser) validate() Errors {
+	var errs Errors
+	for _, v := range p.validators {
+		if err := v.fn(); err != nil {
+			errs = append(errs, &FieldError{
+				Key:   v.key,
+				Field: v.field.Name,
+				Err:   fmt.Errorf("%w: %w", ErrValidation, err),
+			})
+		}
+	}
+	return errs
+}
+
+// setup calls the Setup methods and adds the Teardown methods to the context.
+func (p *Parser) setup() error {
+	for _, init := range p.inits {
+		if init.IsEnabled(p) {
+			if err := init.fn(); err != nil {
+				return err
+			}
+		}
+	}
+	if p.Context == nil {
+		return nil
+	}
+	for _, teardown := range p.teardowns {
+		if teardown.IsEnabled(p) {
+			xcontext.AddCleanup(p.Context, func() { _ = teardown.fn() })
+		}
+	}
+	return nil
+}
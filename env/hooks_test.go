@@ -0,0 +1,108 @@
+package env_test
+
+import (
+	"testing"
+
+	"go.chrisrx.dev/x/assert"
+	"go.chrisrx.dev/x/context"
+	"go.chrisrx.dev/x/env"
+	"go.chrisrx.dev/x/env/testdata/pg"
+)
+
+// calls records the order hooks are called in.
+var calls []string
+
+type HooksChild struct {
+	Name string `env:"NAME" default:"from tag"`
+}
+
+func (c *HooksChild) Default() {
+	calls = append(calls, "child.Default")
+	c.Name = "from Default"
+}
+func (c *HooksChild) Validate() error { calls = append(calls, "child.Validate"); return nil }
+func (c *HooksChild) Setup()          { calls = append(calls, "child.Setup") }
+func (c *HooksChild) Teardown()       { calls = append(calls, "child.Teardown") }
+
+type hooksParent struct {
+	Child HooksChild
+	Other struct {
+		HooksChild
+	}
+}
+
+func (p *hooksParent) Default()        { calls = append(calls, "parent.Default") }
+func (p *hooksParent) Validate() error { calls = append(calls, "parent.Validate"); return nil }
+func (p *hooksParent) Setup() error    { calls = append(calls, "parent.Setup"); return nil }
+func (p *hooksParent) Teardown()       { calls = append(calls, "parent.Teardown") }
+
+func TestHooks(t *testing.T) {
+	t.Run("order", func(t *testing.T) {
+		calls = nil
+		ctx := context.Shutdown()
+		cfg, err := env.ParseFor[hooksParent](env.WithContext(ctx), env.WithSources(env.Map(map[string]string{
+			"OTHER_NAME": "from env",
+		})))
+		assert.NoError(t, err)
+		assert.Equal(t, "from Default", cfg.Child.Name)
+		assert.Equal(t, "from env", cfg.Other.Name)
+		assert.Equal(t, []string{
+			"parent.Default",
+			"child.Default",
+			"child.Default",
+			"parent.Validate",
+			"child.Validate",
+			"child.Validate",
+			"parent.Setup",
+			"child.Setup",
+			"child.Setup",
+		}, calls)
+
+		calls = nil
+		ctx.Close()
+		assert.Equal(t, []string{
+			"parent.Teardown",
+			"child.Teardown",
+			"child.Teardown",
+		}, calls)
+	})
+
+	t.Run("setup disabled", func(t *testing.T) {
+		calls = nil
+		ctx := context.Shutdown()
+		_, err := env.ParseFor[hooksParent](env.WithContext(ctx), env.WithSources(env.Map(map[string]string{
+			"OTHER_SETUP_DISABLED": "true",
+		})))
+		assert.NoError(t, err)
+		ctx.Close()
+		assert.Equal(t, []string{
+			"parent.Default",
+			"child.Default",
+			"child.Default",
+			"parent.Validate",
+			"child.Validate",
+			"child.Validate",
+			"parent.Setup",
+			"child.Setup",
+			"parent.Teardown",
+			"child.Teardown",
+		}, calls)
+	})
+
+	t.Run("validate", func(t *testing.T) {
+		_, err := env.ParseFor[struct {
+			Database pg.Config
+		}](env.WithSources(env.Map(map[string]string{
+			"DATABASE_MIN_POOL_CONNS": "10",
+			"DATABASE_MAX_POOL_CONNS": "5",
+		})))
+		assert.Error(t, env.ErrValidation, err)
+		assert.Equal(t, "DATABASE: failed validation: min pool conns (10) is greater than max pool conns (5)", err.Error())
+
+		_, err = env.ParseFor[pg.Config](env.WithSources(env.Map(map[string]string{
+			"MIN_POOL_CONNS": "10",
+			"MAX_POOL_CONNS": "5",
+		})))
+		assert.Equal(t, "failed validation: min pool conns (10) is greater than max pool conns (5)", err.Error())
+	})
+}
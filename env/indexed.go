@@ -0,0 +1,208 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+
+	"go.chrisrx.dev/x/maps"
+	"go.chrisrx.dev/x/must"
+	"go.chrisrx.dev/x/slices"
+	"go.chrisrx.dev/x/strings"
+	"go.chrisrx.dev/x/structs"
+)
+
+// Lister is implemented by sources that can list the keys they have set. It is
+// used to discover the indexes of slices and maps of structs, e.g. the 0 and 1
+// of BACKENDS_0_HOST and BACKENDS_1_HOST. Slice indexes must be consecutive
+// starting at 0, so that elements keep their positions, and a gap is a parse
+// error. Map keys are lower-cased, e.g. BACKENDS_US_EAST_HOST sets the us_east
+// key.
+type Lister interface {
+	Keys() []string
+}
+
+func (environ) Keys() []string {
+	return slices.Map(os.Environ(), func(kv string) string {
+		k, _, _ := strings.Cut(kv, "=")
+		return k
+	})
+}
+
+func (m mapSource) Keys() []string { return slices.Collect(maps.Keys(m.values)) }
+
+func (f *fileSource) Keys() []string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return slices.Collect(maps.Keys(f.values))
+}
+
+// isIndexed returns whether a type is a slice or map of structs, which are
+// parsed from indexed variables rather than from a single variable. Structs
+// without exported fields can't be set this way, so they aren't included.
+func isIndexed(rt reflect.Type) bool {
+	switch rt.Kind() {
+	case reflect.Slice, reflect.Map:
+	default:
+		return false
+	}
+	elem := rt.Elem()
+	for elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+	rv := reflect.New(elem).Elem()
+	if elem.Kind() != reflect.Struct || structs.HasConversion(rv) || structs.IsWellKnown(rv) {
+		return false
+	}
+	for i := range elem.NumField() {
+		if elem.Field(i).IsExported() {
+			return true
+		}
+	}
+	return false
+}
+
+// placeholder is used in place of the index of a slice or map in the keys
+// returned by [Parser.Fields].
+func placeholder(rt reflect.Type) string {
+	if rt.Kind() == reflect.Map {
+		return "<KEY>"
+	}
+	return "<N>"
+}
+
+// parseIndexed parses a slice or map of structs. Each element is parsed like a
+// nested struct, with the index as an additional prefix, so a []Backend field
+// named BACKENDS reads BACKENDS_0_HOST, BACKENDS_1_HOST and so on. Map
+// elements use the key as the prefix instead, e.g. BACKENDS_PRIMARY_HOST, and
+// the key is lower-cased. Slice indexes with gaps are an error, rather than
+// moving elements to other positions.
+func (p *Parser) parseIndexed(rv reflect.Value, field Field) Errors {
+	prefixes := slices.Map(p.prefixes(field), strings.ToUpper)
+	p.addScope(prefixes)
+	indexes := p.indexes(strings.Join(prefixes, "_"), rv.Type())
+	if len(indexes) == 0 {
+		if field.Required {
+			return Errors{field.error(ErrRequired)}
+		}
+		return nil
+	}
+	var errs Errors
+	switch rv.Kind() {
+	case reflect.Slice:
+		for i, index := range indexes {
+			if index != strconv.Itoa(i) {
+				return Errors{field.error(fmt.Errorf("%w: missing index %d, indexes must be consecutive starting at 0", ErrParse, i))}
+			}
+		}
+		sv := reflect.MakeSlice(rv.Type(), len(indexes), len(indexes))
+		for i, index := range indexes {
+			errs = append(errs, p.parse(sv.Index(i), elemField(field, prefixes, index))...)
+		}
+		rv.Set(sv)
+	case reflect.Map:
+		mv := reflect.MakeMapWithSize(rv.Type(), len(indexes))
+		for _, index := range indexes {
+			key := reflect.New(rv.Type().Key()).Elem()
+			if err := structs.ParseField(strings.ToLower(index), key); err != nil {
+				errs = append(errs, field.error(fmt.Errorf("%w: map key %q: %w", ErrParse, index, err)))
+				continue
+			}
+			elem := reflect.New(rv.Type().Elem()).Elem()
+			errs = append(errs, p.parse(elem, elemField(field, prefixes, index))...)
+			mv.SetMapIndex(key, elem)
+		}
+		rv.Set(mv)
+	}
+	return errs
+}
+
+// elemField returns the field for an element of a slice or map, which is
+// parsed like a nested struct field with the index as its env tag.
+func elemField(field Field, prefixes []string, index string) Field {
+	elem := field
+	elem.Type = field.Type.Elem()
+	elem.Anonymous = false
+	elem.Env = index
+	elem.prefixes = prefixes
+	return elem
+}
+
+// indexes discovers the indexes of a slice or map of structs from the keys of
+// the sources, by matching keys that start with the prefix and end with a key
+// of a field of the element. Slice indexes are sorted by number, and map keys
+// are sorted. Slices are also probed for consecutive indexes starting at 0,
+// for sources that don't implement [Lister].
+func (p *Parser) indexes(prefix string, rt reflect.Type) []string {
+	elem := rt.Elem()
+	for elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+	var suffixes []string
+	for _, child := range fieldsOf(elem) {
+		for _, field := range p.fields(nil, child.Type, child, []reflect.Type{elem}) {
+			if !isValidEnv(field.Key()) {
+				// Fields of nested slices and maps can't be matched, since
+				// their keys have an index as well.
+				continue
+			}
+			suffixes = append(suffixes, field.Key())
+			if isSecret(field) {
+				suffixes = append(suffixes, field.Key()+"_FILE")
+			}
+		}
+	}
+
+	found := make(map[string]struct{})
+	valid := func(index string) bool {
+		if index == "" || index != strings.ToUpper(index) {
+			return false
+		}
+		if rt.Kind() == reflect.Slice {
+			n, err := strconv.Atoi(index)
+			return err == nil && n >= 0 && strconv.Itoa(n) == index
+		}
+		return true
+	}
+	for _, src := range p.sources() {
+		lister, ok := src.(Lister)
+		if !ok {
+			continue
+		}
+		for _, key := range lister.Keys() {
+			rest, ok := strings.CutPrefix(key, prefix+"_")
+			if !ok {
+				continue
+			}
+			for _, suffix := range suffixes {
+				if index, ok := strings.CutSuffix(rest, "_"+suffix); ok && valid(index) {
+					found[index] = struct{}{}
+				}
+			}
+		}
+	}
+	if rt.Kind() == reflect.Slice {
+		for i := 0; ; i++ {
+			index := strconv.Itoa(i)
+			if !slices.ContainsFunc(suffixes, func(suffix string) bool {
+				return p.isSet(prefix + "_" + index + "_" + suffix)
+			}) {
+				break
+			}
+			found[index] = struct{}{}
+		}
+		return slices.SortedFunc(maps.Keys(found), func(a, b string) int {
+			return must.Get0(strconv.Atoi(a)) - must.Get0(strconv.Atoi(b))
+		})
+	}
+	return slices.Sorted(maps.Keys(found))
+}
+
+// isSet returns whether any source has the key set.
+func (p *Parser) isSet(key string) bool {
+	return slices.ContainsFunc(p.sources(), func(src Source) bool {
+		_, ok := src.Lookup(key)
+		return ok
+	})
+}
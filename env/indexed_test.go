@@ -0,0 +1,142 @@
+package env_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.chrisrx.dev/x/assert"
+	"go.chrisrx.dev/x/env"
+)
+
+type backend struct {
+	Host    string        `env:"HOST" required:"true"`
+	Port    int           `env:"PORT" default:"80"`
+	Timeout time.Duration `env:"TIMEOUT" default:"5s"`
+}
+
+// lookupOnly is a source that doesn't implement [env.Lister].
+type lookupOnly map[string]string
+
+func (l lookupOnly) Lookup(key string) (string, bool) {
+	v, ok := l[key]
+	return v, ok
+}
+
+func (lookupOnly) String() string { return "lookup" }
+
+func TestIndexed(t *testing.T) {
+	t.Run("slice", func(t *testing.T) {
+		cfg, err := env.ParseFor[struct {
+			Backends []backend `env:"BACKENDS"`
+		}](env.WithSources(env.Map(map[string]string{
+			"BACKENDS_0_HOST": "a.local",
+			"BACKENDS_1_HOST": "b.local",
+			"BACKENDS_1_PORT": "8080",
+			"BACKENDS_2_HOST": "c.local",
+		})))
+		assert.NoError(t, err)
+		assert.Equal(t, []backend{
+			{Host: "a.local", Port: 80, Timeout: 5 * time.Second},
+			{Host: "b.local", Port: 8080, Timeout: 5 * time.Second},
+			{Host: "c.local", Port: 80, Timeout: 5 * time.Second},
+		}, cfg.Backends)
+	})
+
+	t.Run("map", func(t *testing.T) {
+		cfg, err := env.ParseFor[struct {
+			Backends map[string]*backend
+		}](env.WithSources(env.Map(map[string]string{
+			"BACKENDS_PRIMARY_HOST":   "a.local",
+			"BACKENDS_US_EAST_HOST":   "b.local",
+			"BACKENDS_US_EAST_PORT":   "8080",
+			"BACKENDS_US_EAST_OTHER":  "ignored",
+			"BACKENDS_lowercase_HOST": "ignored",
+		})))
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]*backend{
+			"primary": {Host: "a.local", Port: 80, Timeout: 5 * time.Second},
+			"us_east": {Host: "b.local", Port: 8080, Timeout: 5 * time.Second},
+		}, cfg.Backends)
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		type region struct {
+			Name     string    `env:"NAME"`
+			Backends []backend `env:"BACKENDS"`
+		}
+		cfg, err := env.ParseFor[struct {
+			Regions []region `env:"REGIONS"`
+		}](env.RootPrefix("APP"), env.WithSources(env.Map(map[string]string{
+			"APP_REGIONS_0_NAME":            "us",
+			"APP_REGIONS_0_BACKENDS_0_HOST": "a.local",
+			"APP_REGIONS_0_BACKENDS_1_HOST": "b.local",
+			"APP_REGIONS_1_NAME":            "eu",
+		})))
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(cfg.Regions))
+		assert.Equal(t, "us", cfg.Regions[0].Name)
+		assert.Equal(t, 2, len(cfg.Regions[0].Backends))
+		assert.Equal(t, "b.local", cfg.Regions[0].Backends[1].Host)
+		assert.Equal(t, "eu", cfg.Regions[1].Name)
+		assert.Equal(t, 0, len(cfg.Regions[1].Backends))
+	})
+
+	t.Run("probe", func(t *testing.T) {
+		cfg, err := env.ParseFor[struct {
+			Backends []backend `env:"BACKENDS"`
+		}](env.WithSources(lookupOnly{
+			"BACKENDS_0_HOST": "a.local",
+			"BACKENDS_1_HOST": "b.local",
+			"BACKENDS_3_HOST": "not found",
+		}))
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(cfg.Backends))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := env.ParseFor[struct {
+			Backends []backend `env:"BACKENDS"`
+		}](env.WithSources(env.Map(map[string]string{
+			"BACKENDS_0_HOST": "a.local",
+			"BACKENDS_1_PORT": "http",
+		})))
+		assert.Error(t, `env: 2 errors:
+	BACKENDS_1_HOST: required field not set
+	BACKENDS_1_PORT: cannot parse value: .*`, err)
+
+		_, err = env.ParseFor[struct {
+			Backends []backend `env:"BACKENDS" required:"true"`
+		}](env.WithSources(env.Map(nil)))
+		assert.Error(t, env.ErrRequired, err)
+
+		// Elements aren't moved to close gaps, since their position can matter.
+		_, err = env.ParseFor[struct {
+			Backends []backend `env:"BACKENDS"`
+		}](env.WithSources(env.Map(map[string]string{
+			"BACKENDS_0_HOST": "a.local",
+			"BACKENDS_5_HOST": "b.local",
+		})))
+		assert.Error(t, "BACKENDS: .*missing index 1", err)
+		assert.Error(t, env.ErrParse, err)
+	})
+
+	t.Run("fields", func(t *testing.T) {
+		fields := env.NewParser().Fields(reflect.TypeFor[struct {
+			Backends []backend          `env:"BACKENDS"`
+			Named    map[string]backend `env:"NAMED"`
+		}]())
+		var keys []string
+		for _, field := range fields {
+			keys = append(keys, field.Key())
+		}
+		assert.Equal(t, []string{
+			"BACKENDS_<N>_HOST",
+			"BACKENDS_<N>_PORT",
+			"BACKENDS_<N>_TIMEOUT",
+			"NAMED_<KEY>_HOST",
+			"NAMED_<KEY>_PORT",
+			"NAMED_<KEY>_TIMEOUT",
+		}, keys)
+	})
+}
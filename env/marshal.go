@@ -0,0 +1,252 @@
+package env
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"go.chrisrx.dev/x/convert"
+	"go.chrisrx.dev/x/internal/reflectx"
+	"go.chrisrx.dev/x/maps"
+	"go.chrisrx.dev/x/slices"
+	"go.chrisrx.dev/x/strings"
+	"go.chrisrx.dev/x/structs"
+)
+
+// IncludeSecrets is an option for [Marshal] that includes the values of
+// secrets, which are left out by default.
+func IncludeSecrets() ParserOption {
+	return func(p *Parser) {
+		p.IncludeSecrets = true
+	}
+}
+
+// IncludePrivate is an option for [Marshal] that includes the values of
+// [Private] fields, which are left out by default.
+func IncludePrivate() ParserOption {
+	return func(p *Parser) {
+		p.IncludePrivate = true
+	}
+}
+
+// Marshal returns the environment variables that [Parse] would read the
+// struct v from, using the same names. It is the inverse of [Parse], e.g. to
+// pass a config to a child process. Values are formatted with the conversions
+// registered with [Register] or [encoding.TextMarshaler], and slices and maps
+// are joined with the separator of the field. Nil pointers and empty slices
+// and maps are left out, since they are the same as unset variables.
+// Elements of slices and maps that contain the separator, or map keys that
+// contain "=", can't be read back, so they are an error. Keys of
+// maps of structs must be lower case, since they are lower-cased by Parse.
+func Marshal(v any, opts ...ParserOption) (map[string]string, error) {
+	return NewParser(opts...).Marshal(v)
+}
+
+// MarshalEnviron returns the environment variables of [Marshal] as KEY=VALUE
+// strings sorted by key, the format of [os.Environ] and [exec.Cmd.Env]. It
+// isn't named Environ, since that is the [Source] for the environment of the
+// process.
+func MarshalEnviron(v any, opts ...ParserOption) ([]string, error) {
+	m, err := Marshal(v, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return slices.Map(slices.Sorted(maps.Keys(m)), func(key string) string {
+		return key + "=" + m[key]
+	}), nil
+}
+
+// Marshal returns the environment variables that [Parser.Parse] would read
+// the struct v from. See [Marshal].
+func (p *Parser) Marshal(v any) (map[string]string, error) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("must provide a struct, received %T", v)
+	}
+	// Values must be addressable for methods with pointer receivers, like
+	// UnmarshalText, to be found the same as when parsing.
+	rv = reflectx.MakeAddressable(rv).Elem()
+	m := make(map[string]string)
+	if errs := p.marshalStruct(m, rv); len(errs) > 0 {
+		return nil, errs
+	}
+	return m, nil
+}
+
+// marshalStruct adds the variables for the fields of the root struct.
+func (p *Parser) marshalStruct(m map[string]string, rv reflect.Value) Errors {
+	var errs Errors
+	for i, field := range fieldsOf(rv.Type(), p.RootPrefix) {
+		errs = append(errs, p.marshal(m, rv.Field(i), field)...)
+	}
+	return errs
+}
+
+var privateType = reflect.TypeFor[Private]()
+
+// marshal adds the variables for a field, following the same steps as
+// [Parser.parse].
+func (p *Parser) marshal(m map[string]string, rv reflect.Value, field Field) Errors {
+	switch {
+	case !field.IsExported():
+		return nil
+	case field.Type == privateType && !p.IncludePrivate:
+		return nil
+	case isSecret(field) && !p.IncludeSecrets:
+		return nil
+	}
+
+	switch {
+	case rv.Kind() == reflect.Pointer, rv.Kind() == reflect.Interface:
+		if rv.IsNil() {
+			return nil
+		}
+		return p.marshal(m, rv.Elem(), field)
+	case structs.HasConversion(rv), structs.IsWellKnown(rv):
+		return p.marshalSingular(m, rv, field)
+	case isIndexed(rv.Type()):
+		prefixes := slices.Map(p.prefixes(field), strings.ToUpper)
+		var errs Errors
+		switch rv.Kind() {
+		case reflect.Slice:
+			for i := range rv.Len() {
+				errs = append(errs, p.marshal(m, rv.Index(i), elemField(field, prefixes, strconv.Itoa(i)))...)
+			}
+		case reflect.Map:
+			for _, key := range rv.MapKeys() {
+				index, _, err := format(key, field)
+				if err != nil {
+					errs = append(errs, field.error(err))
+					continue
+				}
+				if index != strings.ToLower(index) {
+					// Keys are lower-cased when parsed, so they would be read
+					// back as a different key.
+					errs = append(errs, field.error(fmt.Errorf("map key %q must be lower case", index)))
+					continue
+				}
+				elem := reflectx.MakeAddressable(rv.MapIndex(key)).Elem()
+				errs = append(errs, p.marshal(m, elem, elemField(field, prefixes, strings.ToUpper(index)))...)
+			}
+		}
+		return errs
+	case rv.Kind() == reflect.Struct:
+		prefixes := p.prefixes(field)
+		var errs Errors
+		for i, child := range fieldsOf(rv.Type(), prefixes...) {
+			errs = append(errs, p.marshal(m, rv.Field(i), child)...)
+		}
+		return errs
+	default:
+		return p.marshalSingular(m, rv, field)
+	}
+}
+
+func (p *Parser) marshalSingular(m map[string]string, rv reflect.Value, field Field) Errors {
+	if !p.RequireTagged && field.Env == "" {
+		return nil
+	}
+	s, ok, err := format(rv, field)
+	if err != nil {
+		return Errors{field.error(err)}
+	}
+	if ok {
+		m[field.Key()] = s
+	}
+	return nil
+}
+
+var stringType = reflect.TypeFor[string]()
+
+// format formats a value as a string that is parsed back into the same value.
+// It returns false if the value should be left unset.
+func format(rv reflect.Value, field Field) (string, bool, error) {
+	if rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return "", false, nil
+		}
+		return format(rv.Elem(), field)
+	}
+	v := reflectx.Interface(rv)
+	if s, ok := v.(interface{ reveal() any }); ok {
+		return format(reflect.ValueOf(s.reveal()), field)
+	}
+	if fn, ok := convert.Lookup(rv.Type(), stringType); ok {
+		s, err := fn(v, convert.Layout(field.Layout()), convert.Separator(field.Separator()))
+		if err != nil {
+			return "", false, err
+		}
+		return s.(string), true, nil
+	}
+	if m, ok := v.(encoding.TextMarshaler); ok {
+		b, err := m.MarshalText()
+		if err != nil {
+			return "", false, err
+		}
+		return string(b), true, nil
+	}
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String(), true, nil
+	case reflect.Bool:
+		return strconv.FormatBool(rv.Bool()), true, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), true, nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'g', -1, rv.Type().Bits()), true, nil
+	case reflect.Slice, reflect.Array:
+		if rv.Len() == 0 {
+			return "", false, nil
+		}
+		elems, err := slices.MapErr(slices.N(rv.Len()), func(i int) (string, error) {
+			s, _, err := format(rv.Index(i), field)
+			if err != nil {
+				return "", err
+			}
+			return s, checkElem(s, field.Separator())
+		})
+		if err != nil {
+			return "", false, err
+		}
+		return strings.Join(elems, field.Separator()), true, nil
+	case reflect.Map:
+		if rv.Len() == 0 {
+			return "", false, nil
+		}
+		elems, err := slices.MapErr(rv.MapKeys(), func(key reflect.Value) (string, error) {
+			k, _, err := format(key, field)
+			if err != nil {
+				return "", err
+			}
+			if err := checkElem(k, field.Separator(), "="); err != nil {
+				return "", err
+			}
+			v, _, err := format(rv.MapIndex(key), field)
+			if err != nil {
+				return "", err
+			}
+			return k + "=" + v, checkElem(v, field.Separator())
+		})
+		if err != nil {
+			return "", false, err
+		}
+		return strings.Join(slices.Sort(elems), field.Separator()), true, nil
+	default:
+		return "", false, fmt.Errorf("cannot marshal value: %v", rv.Type())
+	}
+}
+
+// checkElem returns an error if an element of a slice or map contains a
+// separator, since it would be split into more elements when parsed. Values
+// aren't escaped, so that they are read the same by [Parse].
+func checkElem(s string, seps ...string) error {
+	for _, sep := range seps {
+		if strings.Contains(s, sep) {
+			return fmt.Errorf("cannot marshal element %q: contains separator %q", s, sep)
+		}
+	}
+	return nil
+}
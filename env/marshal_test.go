@@ -0,0 +1,133 @@
+package env_test
+
+import (
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"go.chrisrx.dev/x/assert"
+	"go.chrisrx.dev/x/env"
+	"go.chrisrx.dev/x/env/testdata/pg"
+	"go.chrisrx.dev/x/maps"
+)
+
+type marshalConfig struct {
+	Name     string            `env:"NAME"`
+	Debug    bool              `env:"DEBUG"`
+	Ratio    float64           `env:"RATIO"`
+	Timeout  time.Duration     `env:"TIMEOUT"`
+	Started  time.Time         `env:"STARTED" layout:"2006-01-02"`
+	Endpoint *url.URL          `env:"ENDPOINT"`
+	IP       net.IP            `env:"IP"`
+	Hosts    []string          `env:"HOSTS" sep:";"`
+	Labels   map[string]string `env:"LABELS"`
+	Empty    []int             `env:"EMPTY"`
+	Unset    *int              `env:"UNSET"`
+	Untagged string
+	Password string             `env:"PASSWORD" secret:"true"`
+	Token    env.Secret[string] `env:"TOKEN"`
+	Conn     env.Private        `env:"CONN"`
+	Database pg.Config
+	Backends []struct {
+		Host string `env:"HOST"`
+	} `env:"BACKENDS"`
+	Regions map[string]struct {
+		Zone string `env:"ZONE"`
+	} `env:"REGIONS"`
+}
+
+func TestMarshal(t *testing.T) {
+	values := map[string]string{
+		"APP_NAME":                     "example",
+		"APP_DEBUG":                    "true",
+		"APP_RATIO":                    "0.5",
+		"APP_TIMEOUT":                  "1m30s",
+		"APP_STARTED":                  "2025-01-02",
+		"APP_ENDPOINT":                 "https://example.com/api",
+		"APP_IP":                       "10.0.0.1",
+		"APP_HOSTS":                    "a;b",
+		"APP_LABELS":                   "env=prod,team=infra",
+		"APP_PASSWORD":                 "hunter2",
+		"APP_TOKEN":                    "abc123",
+		"APP_DATABASE_HOST":            "db.local",
+		"APP_DATABASE_PORT":            "5433",
+		"APP_DATABASE_USERNAME":        "",
+		"APP_DATABASE_PASSWORD":        "",
+		"APP_DATABASE_NAME":            "app",
+		"APP_DATABASE_CONNECT_TIMEOUT": "30s",
+		"APP_DATABASE_SSL_MODE":        "verify-full",
+		"APP_DATABASE_MIN_POOL_CONNS":  "0",
+		"APP_DATABASE_MAX_POOL_CONNS":  "0",
+		"APP_BACKENDS_0_HOST":          "a.local",
+		"APP_BACKENDS_1_HOST":          "b.local",
+		"APP_REGIONS_US_EAST_ZONE":     "1a",
+	}
+	opts := []env.ParserOption{env.RootPrefix("APP"), env.WithSources(env.Map(values))}
+	cfg, err := env.ParseFor[marshalConfig](opts...)
+	assert.NoError(t, err)
+	cfg.Conn = "private"
+
+	t.Run("round trip", func(t *testing.T) {
+		m, err := env.Marshal(cfg, env.RootPrefix("APP"), env.IncludeSecrets())
+		assert.NoError(t, err)
+		// Parse allocates nil pointers, so Unset is marshaled too.
+		expected := maps.Clone(values)
+		expected["APP_UNSET"] = "0"
+		assert.Equal(t, expected, m)
+
+		parsed, err := env.ParseFor[marshalConfig](env.RootPrefix("APP"), env.WithSources(env.Map(m)))
+		assert.NoError(t, err)
+		parsed.Conn = "private"
+		assert.Equal(t, cfg, parsed)
+	})
+
+	t.Run("secrets and private", func(t *testing.T) {
+		m, err := env.Marshal(&cfg, env.RootPrefix("APP"))
+		assert.NoError(t, err)
+		for _, key := range []string{"APP_PASSWORD", "APP_TOKEN", "APP_CONN"} {
+			_, ok := m[key]
+			assert.Equal(t, false, ok, key)
+		}
+
+		m, err = env.Marshal(&cfg, env.RootPrefix("APP"), env.IncludePrivate())
+		assert.NoError(t, err)
+		assert.Equal(t, "private", m["APP_CONN"])
+	})
+
+	t.Run("environ", func(t *testing.T) {
+		environ, err := env.MarshalEnviron(struct {
+			B string `env:"B"`
+			A int    `env:"A"`
+		}{B: "x y", A: 1})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"A=1", "B=x y"}, environ)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := env.Marshal(struct {
+			C chan int `env:"C"`
+		}{C: make(chan int)})
+		assert.Error(t, "C: cannot marshal value: chan int", err)
+
+		// Map keys are lower-cased when parsed, so other keys can't be read back.
+		_, err = env.Marshal(struct {
+			Regions map[string]struct {
+				Zone string `env:"ZONE"`
+			} `env:"REGIONS"`
+		}{Regions: map[string]struct {
+			Zone string `env:"ZONE"`
+		}{"US_East": {Zone: "1a"}}})
+		assert.Error(t, `REGIONS: map key "US_East" must be lower case`, err)
+
+		// Elements containing the separator would be split when parsed.
+		_, err = env.Marshal(struct {
+			Hosts []string `env:"HOSTS"`
+		}{Hosts: []string{"a,b"}})
+		assert.Error(t, `HOSTS: cannot marshal element "a,b": contains separator ","`, err)
+		_, err = env.Marshal(struct {
+			Labels map[string]string `env:"LABELS"`
+		}{Labels: map[string]string{"a=b": "c"}})
+		assert.Error(t, `LABELS: cannot marshal element "a=b": contains separator "="`, err)
+	})
+}
@@ -6,19 +6,39 @@ import (
 	"reflect"
 	"unsafe"
 
+	"go.chrisrx.dev/x/expr"
+	"go.chrisrx.dev/x/slices"
 	"go.chrisrx.dev/x/strings"
 )
 
 // Print prints a representation of a struct to standard output. It using the
 // same rules as [Parser].
 func Print(v any, opts ...ParserOption) {
-	parser := NewParser(opts...)
+	NewParser(opts...).Print(v)
+}
+
+// Print prints a representation of a struct to standard output, see [Print].
+// With the [ShowProvenance] option, it also shows where each value came from
+// in the last call to [Parser.Parse], so v should be the struct that was
+// parsed by p.
+func (parser *Parser) Print(v any) {
 	p := printer{
 		DisableAutoPrefix: parser.DisableAutoPrefix,
 		RootPrefix:        parser.RootPrefix,
 		RequireTagged:     parser.RequireTagged,
+		ExprOptions:       parser.ExprOptions,
 		ptrs:              make(ptrmap),
 	}
+	if parser.ShowProvenance {
+		explanations, err := parser.Explain(v)
+		if err != nil {
+			panic(err)
+		}
+		p.explained = make(map[string]Explanation)
+		for _, e := range explanations {
+			p.explained[e.Key] = e
+		}
+	}
 	if err := p.Print(v); err != nil {
 		panic(err)
 	}
@@ -28,6 +48,11 @@ type printer struct {
 	DisableAutoPrefix bool
 	RootPrefix        string
 	RequireTagged     bool
+	ExprOptions       []expr.Option
+
+	// explained is where the values of fields came from, by key, if
+	// provenance is shown.
+	explained map[string]Explanation
 
 	ptrs   ptrmap
 	indent int
@@ -85,7 +110,37 @@ func (p *printer) print(rv reflect.Value, field Field) {
 				fmt.Print(strings.Repeat("  ", p.indent))
 				fmt.Printf("layout=%s\n", field.Layout())
 			}
-			p.print(rv.Field(i), field)
+			if field.Validate != "" {
+				fmt.Print(strings.Repeat("  ", p.indent))
+				fmt.Printf("validate=%s\n", field.Validate)
+				for _, b := range p.builtins(field) {
+					fmt.Print(strings.Repeat("  ", p.indent))
+					fmt.Printf("see=%s\n", b.URL())
+				}
+			}
+			if e, ok := p.explained[field.Key()]; ok && field.Env != "" {
+				fmt.Print(strings.Repeat("  ", p.indent))
+				fmt.Printf("origin=%s\n", e.Origin)
+				if e.Source != nil {
+					fmt.Print(strings.Repeat("  ", p.indent))
+					fmt.Printf("source=%s\n", e.Source)
+				}
+				if e.Raw != "" {
+					fmt.Print(strings.Repeat("  ", p.indent))
+					fmt.Printf("raw=%s\n", e.Raw)
+				}
+			}
+			switch {
+			case isSecret(field):
+				fmt.Print(strings.Repeat("  ", p.indent))
+				fmt.Println("secret=true")
+				if !rv.Field(i).IsZero() {
+					fmt.Print(strings.Repeat("  ", p.indent))
+					fmt.Printf("value=%s\n", redacted)
+				}
+			default:
+				p.print(rv.Field(i), field)
+			}
 			fmt.Print(strings.Repeat("  ", p.indent-1))
 			fmt.Println("}")
 			p.indent--
@@ -98,6 +153,22 @@ func (p *printer) print(rv reflect.Value, field Field) {
 	}
 }
 
+// builtins returns the documented builtins referenced by the validation
+// expression of a field, so that they can be linked to the expr reference.
+func (p *printer) builtins(field Field) []expr.Builtin {
+	opts := append(slices.Clone(p.ExprOptions),
+		expr.Declare(field.Name, field.Type),
+		expr.Declare("self", field.Type),
+	)
+	prog, err := expr.Compile(field.Validate, opts...)
+	if err != nil {
+		return nil
+	}
+	return slices.Filter(prog.Builtins(), func(b expr.Builtin) bool {
+		return b.Doc != ""
+	})
+}
+
 type ptrmap map[unsafe.Pointer]reflect.Value
 
 func (p ptrmap) add(v reflect.Value) { p[unsafe.Pointer(v.Pointer())] = v.Elem() }
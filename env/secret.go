@@ -0,0 +1,152 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+
+	"go.chrisrx.dev/x/strings"
+	"go.chrisrx.dev/x/structs"
+)
+
+// redacted replaces the values of secrets when printing.
+const redacted = "REDACTED"
+
+// Secret is a value that is read as a secret, the same as a field with the
+// `secret:"true"` tag. The value is redacted when formatted, so that it isn't
+// accidentally logged:
+//
+//	type Config struct {
+//		Password env.Secret[string] `env:"PASSWORD"`
+//	}
+type Secret[T any] struct {
+	value T
+}
+
+// Get returns the value of the secret.
+func (s Secret[T]) Get() T {
+	return s.value
+}
+
+// String returns a redacted value.
+func (s Secret[T]) String() string { return redacted }
+
+// GoString returns a redacted value.
+func (s Secret[T]) GoString() string { return redacted }
+
+// UnmarshalText parses the value of the secret. Fields of a config are parsed
+// with the layout and sep tags of the field instead, see [Field.set].
+func (s *Secret[T]) UnmarshalText(text []byte) error {
+	v, err := structs.ParseFieldAs[T](string(text))
+	if err != nil {
+		return err
+	}
+	s.value = v
+	return nil
+}
+
+func (Secret[T]) secret() {}
+
+// elem returns the value of the secret, so that it can be set by a parser.
+func (s *Secret[T]) elem() reflect.Value {
+	return reflect.ValueOf(&s.value).Elem()
+}
+
+// reveal returns the value of the secret for [Marshal].
+func (s Secret[T]) reveal() any { return s.value }
+
+var secretType = reflect.TypeFor[interface{ secret() }]()
+
+// secretElem returns the value of a [Secret], or the value itself for any
+// other type.
+func secretElem(rv reflect.Value) reflect.Value {
+	if rv.CanAddr() {
+		if s, ok := rv.Addr().Interface().(interface{ elem() reflect.Value }); ok {
+			return s.elem()
+		}
+	}
+	return rv
+}
+
+// isSecret returns whether a field is read as a secret.
+func isSecret(field Field) bool {
+	return field.Secret || field.Type.Implements(secretType)
+}
+
+// SecretResolver resolves references to secrets stored outside of the
+// environment, e.g. "vault://secret/db#password". Resolvers are added for a
+// URL scheme with [WithSecretResolver].
+type SecretResolver interface {
+	// Resolve returns the value of the secret for the reference, which
+	// includes the scheme.
+	Resolve(ref string) (string, error)
+}
+
+// SecretResolverFunc is an adapter to use a function as a [SecretResolver].
+type SecretResolverFunc func(ref string) (string, error)
+
+// Resolve calls fn(ref).
+func (fn SecretResolverFunc) Resolve(ref string) (string, error) {
+	return fn(ref)
+}
+
+// WithSecretResolver is an option for [Parser] that resolves values of
+// secrets starting with "<scheme>://" using the resolver. The "file" scheme is
+// resolved by reading the file unless another resolver is set for it.
+func WithSecretResolver(scheme string, r SecretResolver) ParserOption {
+	return func(p *Parser) {
+		if p.SecretResolvers == nil {
+			p.SecretResolvers = make(map[string]SecretResolver)
+		}
+		p.SecretResolvers[scheme] = r
+	}
+}
+
+// fileResolver resolves "file://" references by reading the file.
+var fileResolver = SecretResolverFunc(func(ref string) (string, error) {
+	return readSecretFile(strings.TrimPrefix(ref, "file://"))
+})
+
+// readSecretFile reads a secret from a file. A trailing newline is removed,
+// since files are often written with one.
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// resolveSecret returns the value of a secret field. If the variable for the
+// field isn't set, the secret is read from the file named by the variable with
+// a _FILE suffix, so the variable takes precedence when both are set. Values that start with the scheme of a resolver are resolved
+// by it, and any other values are used as is.
+func (p *Parser) resolveSecret(field Field, s string, ok bool) (string, bool, error) {
+	if !ok {
+		path, ok := p.lookup(field.Key() + "_FILE")
+		if !ok {
+			return "", false, nil
+		}
+		s, err := readSecretFile(path)
+		if err != nil {
+			return "", false, fmt.Errorf("cannot read secret: %w", err)
+		}
+		return s, true, nil
+	}
+	scheme, _, found := strings.Cut(s, "://")
+	if !found {
+		return s, true, nil
+	}
+	r, found := p.SecretResolvers[scheme]
+	if !found && scheme == "file" {
+		r, found = fileResolver, true
+	}
+	if !found {
+		return s, true, nil
+	}
+	s, err := r.Resolve(s)
+	if err != nil {
+		return "", false, fmt.Errorf("cannot resolve secret: %w", err)
+	}
+	return s, true, nil
+}
@@ -0,0 +1,126 @@
+package env_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"go.chrisrx.dev/x/assert"
+	"go.chrisrx.dev/x/env"
+)
+
+type secretConfig struct {
+	Password string             `env:"PASSWORD" secret:"true"`
+	Token    env.Secret[string] `env:"TOKEN"`
+	Port     env.Secret[int]    `env:"PORT"`
+}
+
+func TestSecrets(t *testing.T) {
+	t.Run("literal", func(t *testing.T) {
+		cfg, err := env.ParseFor[secretConfig](env.WithSources(env.Map(map[string]string{
+			"PASSWORD": "hunter2",
+			"TOKEN":    "abc123",
+			"PORT":     "5432",
+		})))
+		assert.NoError(t, err)
+		assert.Equal(t, "hunter2", cfg.Password)
+		assert.Equal(t, "abc123", cfg.Token.Get())
+		assert.Equal(t, 5432, cfg.Port.Get())
+	})
+
+	t.Run("file reference", func(t *testing.T) {
+		password := writeFile(t, "password", "hunter2\n")
+		token := writeFile(t, "token", "abc123")
+		cfg, err := env.ParseFor[secretConfig](env.WithSources(env.Map(map[string]string{
+			"PASSWORD": "file://" + password,
+			"TOKEN":    "file://" + token,
+		})))
+		assert.NoError(t, err)
+		assert.Equal(t, "hunter2", cfg.Password)
+		assert.Equal(t, "abc123", cfg.Token.Get())
+	})
+
+	t.Run("file variable", func(t *testing.T) {
+		password := writeFile(t, "password", "hunter2\n")
+		port := writeFile(t, "port", "5432\n")
+		cfg, err := env.ParseFor[secretConfig](env.WithSources(env.Map(map[string]string{
+			"PASSWORD_FILE": password,
+			"PORT_FILE":     port,
+		})))
+		assert.NoError(t, err)
+		assert.Equal(t, "hunter2", cfg.Password)
+		assert.Equal(t, 5432, cfg.Port.Get())
+
+		// The variable takes precedence over the file.
+		cfg, err = env.ParseFor[secretConfig](env.WithSources(env.Map(map[string]string{
+			"PASSWORD":      "from-env",
+			"PASSWORD_FILE": password,
+		})))
+		assert.NoError(t, err)
+		assert.Equal(t, "from-env", cfg.Password)
+	})
+
+	t.Run("field tags", func(t *testing.T) {
+		cfg, err := env.ParseFor[struct {
+			Hosts   env.Secret[[]string]  `env:"HOSTS" sep:";"`
+			Expires env.Secret[time.Time] `env:"EXPIRES" layout:"2006-01-02"`
+			Keys    env.Secret[[]string]  `env:"KEYS" sep:";" default:"a;b"`
+		}](env.WithSources(env.Map(map[string]string{
+			"HOSTS":   "a,1;b,2",
+			"EXPIRES": "2030-01-02",
+		})))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a,1", "b,2"}, cfg.Hosts.Get())
+		assert.Equal(t, time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC), cfg.Expires.Get())
+		assert.Equal(t, []string{"a", "b"}, cfg.Keys.Get())
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := env.ParseFor[secretConfig](env.WithSources(env.Map(map[string]string{
+			"PASSWORD": "file:///does/not/exist",
+		})))
+		assert.Error(t, "PASSWORD: cannot resolve secret: .*no such file or directory", err)
+	})
+
+	t.Run("resolver", func(t *testing.T) {
+		vault := env.SecretResolverFunc(func(ref string) (string, error) {
+			switch ref {
+			case "vault://db#password":
+				return "hunter2", nil
+			}
+			return "", errors.New("secret not found")
+		})
+		cfg, err := env.ParseFor[secretConfig](
+			env.WithSources(env.Map(map[string]string{
+				"PASSWORD": "vault://db#password",
+				"TOKEN":    "https://not-a-reference",
+			})),
+			env.WithSecretResolver("vault", vault),
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, "hunter2", cfg.Password)
+		assert.Equal(t, "https://not-a-reference", cfg.Token.Get())
+
+		_, err = env.ParseFor[secretConfig](
+			env.WithSources(env.Map(map[string]string{"PASSWORD": "vault://db#missing"})),
+			env.WithSecretResolver("vault", vault),
+		)
+		assert.Error(t, "PASSWORD: cannot resolve secret: secret not found", err)
+	})
+
+	t.Run("redacted", func(t *testing.T) {
+		cfg, err := env.ParseFor[secretConfig](env.WithSources(env.Map(map[string]string{
+			"TOKEN": "abc123",
+		})))
+		assert.NoError(t, err)
+		assert.Equal(t, false, strings.Contains(fmt.Sprintf("%v %+v %#v", cfg.Token, cfg, cfg), "abc123"))
+
+		_, err = env.ParseFor[struct {
+			Password string `env:"PASSWORD" secret:"true" validate:"len(Password) > 8"`
+		}](env.WithSources(env.Map(map[string]string{"PASSWORD": "hunter2"})))
+		assert.Error(t, env.ErrValidation, err)
+		assert.Equal(t, false, strings.Contains(err.Error(), "hunter2"))
+	})
+}
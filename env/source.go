@@ -0,0 +1,346 @@
+package env
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"sync"
+
+	"go.chrisrx.dev/x/maps"
+	"go.chrisrx.dev/x/slices"
+	"go.chrisrx.dev/x/strings"
+)
+
+// Source is a source of values for fields. Values are looked up by the
+// environment variable name of a field, e.g. "DB_HOST".
+type Source interface {
+	// Lookup returns the value for the key, and whether it is set.
+	Lookup(key string) (string, bool)
+
+	// String describes the source, e.g. "environ" or "dotenv:.env".
+	String() string
+}
+
+// WithSources is an option for [Parser] that sets the sources values are read
+// from, in order of precedence. The value for a field is read from the first
+// source that has it set. Environment variables are only read if [Environ] is
+// one of the sources, which is the default when this option isn't used:
+//
+//	env.Parse(&cfg, env.WithSources(env.Environ(), dotenv))
+func WithSources(sources ...Source) ParserOption {
+	return func(p *Parser) {
+		p.Sources = sources
+	}
+}
+
+type environ struct{}
+
+// Environ returns a [Source] for the environment variables of the process.
+func Environ() Source {
+	return environ{}
+}
+
+func (environ) Lookup(key string) (string, bool) { return os.LookupEnv(key) }
+func (environ) String() string                   { return "environ" }
+
+type mapSource struct {
+	name   string
+	values map[string]string
+}
+
+// Map returns a [Source] for the values of a map, keyed by environment
+// variable name. This is useful for tests, since it doesn't require setting
+// environment variables for the process.
+func Map(values map[string]string) Source {
+	return mapSource{name: "map", values: maps.Clone(values)}
+}
+
+func (m mapSource) Lookup(key string) (string, bool) {
+	v, ok := m.values[key]
+	return v, ok
+}
+
+func (m mapSource) String() string { return m.name }
+
+// Reloader is implemented by sources that can read their values again, such
+// as the file sources. [Watcher] reloads sources that implement it before
+// parsing again.
+type Reloader interface {
+	Reload() error
+}
+
+// fileSource is a source with values read from a file.
+type fileSource struct {
+	name string
+	load func() (map[string]string, error)
+
+	mu     sync.RWMutex
+	values map[string]string
+}
+
+func newFileSource(name string, load func() (map[string]string, error)) (Source, error) {
+	f := &fileSource{name: name, load: load}
+	if err := f.Reload(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *fileSource) Lookup(key string) (string, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	v, ok := f.values[key]
+	return v, ok
+}
+
+func (f *fileSource) String() string { return f.name }
+
+// Reload reads the file again. The previous values are kept if reading fails.
+func (f *fileSource) Reload() error {
+	values, err := f.load()
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	f.values = values
+	f.mu.Unlock()
+	return nil
+}
+
+// DotEnv returns a [Source] for a .env file. Each line of the file sets a
+// variable with KEY=VALUE, optionally preceded by "export". Blank lines and
+// lines starting with # are ignored. Values can be enclosed in single quotes,
+// which are taken literally, or double quotes, which interpret escape
+// sequences like \n. Unquoted values end at the start of a # comment.
+//
+// The source implements [Reloader], which reads the file again.
+func DotEnv(path string) (Source, error) {
+	return newFileSource("dotenv:"+path, func() (map[string]string, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		values, err := parseDotEnv(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return values, nil
+	})
+}
+
+func parseDotEnv(data []byte) (map[string]string, error) {
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || !isValidEnv(key) {
+			return nil, fmt.Errorf("line %d: invalid variable: %q", n, line)
+		}
+		value = strings.TrimSpace(value)
+		switch {
+		case strings.HasPrefix(value, `"`):
+			end := closingQuote(value)
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated quoted value", n)
+			}
+			s, err := strconv.Unquote(value[:end+1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", n, err)
+			}
+			value = s
+		case strings.HasPrefix(value, "'"):
+			end := strings.IndexByte(value[1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated quoted value", n)
+			}
+			value = value[1 : end+1]
+		default:
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = strings.TrimSpace(value[:i])
+			}
+		}
+		values[key] = value
+	}
+	return values, scanner.Err()
+}
+
+// closingQuote returns the index of the double quote closing a quoted string,
+// skipping escaped quotes.
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+// JSONFile returns a [Source] for a JSON config file. Nested objects are
+// flattened into environment variable names by joining the keys with "_", so
+// {"db": {"host": "localhost"}} sets DB_HOST. Arrays are joined with ",", the
+// default separator for slices.
+//
+// The source implements [Reloader], which reads the file again.
+func JSONFile(path string) (Source, error) {
+	return newFileSource("json:"+path, func() (map[string]string, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		var v map[string]any
+		if err := dec.Decode(&v); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		values := make(map[string]string)
+		if err := flatten(values, nil, v); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return values, nil
+	})
+}
+
+func flatten(values map[string]string, keys []string, v any) error {
+	key := strings.ToUpper(strings.Join(slices.Map(keys, strings.ToSnakeCase), "_"))
+	switch v := v.(type) {
+	case map[string]any:
+		for k, elem := range v {
+			if err := flatten(values, append(slices.Clone(keys), k), elem); err != nil {
+				return err
+			}
+		}
+	case []any:
+		elems, err := slices.MapErr(v, func(elem any) (string, error) {
+			switch elem.(type) {
+			case map[string]any, []any:
+				return "", fmt.Errorf("%s: arrays cannot nest arrays or objects", key)
+			}
+			return fmt.Sprint(elem), nil
+		})
+		if err != nil {
+			return err
+		}
+		values[key] = strings.Join(elems, ",")
+	case nil:
+	default:
+		values[key] = fmt.Sprint(v)
+	}
+	return nil
+}
+
+type flagSource struct {
+	fs     *flag.FlagSet
+	values map[string]*flagValue
+}
+
+// Flags returns a [Source] for command-line flags. A flag is defined for each
+// field of v that would be parsed by [Parser], named after the environment
+// variable of the field in lower kebab case, e.g. DB_HOST is --db-host. The
+// parser options must be the same as the ones used for parsing so that the
+// names match. Only flags that are set in args are used as values.
+//
+// A [flag.ErrHelp] error is returned if -h or -help is set in args, after the
+// usage has been printed.
+func Flags(v any, args []string, opts ...ParserOption) (Source, error) {
+	rt := reflect.TypeOf(v)
+	for rt != nil && rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	if rt == nil || rt.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("must provide a struct pointer, received %T", v)
+	}
+	src := &flagSource{
+		fs:     flag.NewFlagSet(os.Args[0], flag.ContinueOnError),
+		values: make(map[string]*flagValue),
+	}
+	for _, field := range NewParser(opts...).Fields(rt) {
+		key := field.Key()
+		if _, ok := src.values[key]; ok || !isValidEnv(key) {
+			continue
+		}
+		fv := &flagValue{isBool: field.Type.Kind() == reflect.Bool}
+		src.values[key] = fv
+		usage := "env " + key
+		if field.Default() != "" {
+			usage += " (default " + strconv.Quote(field.Default()) + ")"
+		}
+		src.fs.Var(fv, flagName(key), usage)
+	}
+	if err := src.fs.Parse(args); err != nil {
+		return nil, err
+	}
+	return src, nil
+}
+
+func flagName(key string) string {
+	return strings.ReplaceAll(strings.ToLower(key), "_", "-")
+}
+
+func (f *flagSource) Lookup(key string) (string, bool) {
+	v, ok := f.values[key]
+	if !ok || !v.set {
+		return "", false
+	}
+	return v.value, true
+}
+
+func (f *flagSource) String() string { return "flags" }
+
+type flagValue struct {
+	value  string
+	set    bool
+	isBool bool
+}
+
+func (f *flagValue) String() string { return f.value }
+
+func (f *flagValue) Set(s string) error {
+	f.value, f.set = s, true
+	return nil
+}
+
+func (f *flagValue) IsBoolFlag() bool { return f.isBool }
+
+// lookup returns the value for a key from the first source that has it set.
+func (p *Parser) lookup(key string) (string, bool) {
+	for _, src := range p.sources() {
+		if v, ok := src.Lookup(key); ok {
+			if p.origins == nil {
+				p.origins = make(map[string]Source)
+			}
+			p.origins[key] = src
+			return v, true
+		}
+	}
+	return "", false
+}
+
+func (p *Parser) sources() []Source {
+	if p.Sources == nil {
+		return []Source{Environ()}
+	}
+	return p.Sources
+}
+
+// Source returns the source the value of an environment variable was read
+// from by the last call to [Parser.Parse]. It returns false if the variable
+// wasn't set by any source, e.g. when the default value was used.
+func (p *Parser) Source(key string) (Source, bool) {
+	src, ok := p.origins[key]
+	return src, ok
+}
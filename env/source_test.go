@@ -0,0 +1,168 @@
+package env_test
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.chrisrx.dev/x/assert"
+	"go.chrisrx.dev/x/env"
+)
+
+type sourceConfig struct {
+	Debug   bool          `env:"DEBUG"`
+	Timeout time.Duration `env:"TIMEOUT" default:"5s"`
+	DB      struct {
+		Host  string   `env:"HOST"`
+		Port  int      `env:"PORT" default:"5432"`
+		Hosts []string `env:"HOSTS"`
+	}
+}
+
+func writeFile(t *testing.T, name, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSources(t *testing.T) {
+	t.Run("map", func(t *testing.T) {
+		cfg, err := env.ParseFor[sourceConfig](env.WithSources(env.Map(map[string]string{
+			"DEBUG":   "true",
+			"DB_HOST": "localhost",
+		})))
+		assert.NoError(t, err)
+		assert.Equal(t, true, cfg.Debug)
+		assert.Equal(t, 5*time.Second, cfg.Timeout)
+		assert.Equal(t, "localhost", cfg.DB.Host)
+		assert.Equal(t, 5432, cfg.DB.Port)
+	})
+
+	t.Run("precedence", func(t *testing.T) {
+		assert.WithEnviron(t, map[string]string{
+			"DB_HOST": "from-environ",
+			"DB_PORT": "1",
+		}, func() {
+			p := env.NewParser(env.WithSources(
+				env.Map(map[string]string{"DB_HOST": "from-map"}),
+				env.Environ(),
+			))
+			var cfg sourceConfig
+			assert.NoError(t, p.Parse(&cfg))
+			assert.Equal(t, "from-map", cfg.DB.Host)
+			assert.Equal(t, 1, cfg.DB.Port)
+
+			src, ok := p.Source("DB_HOST")
+			assert.Equal(t, true, ok)
+			assert.Equal(t, "map", src.String())
+			src, ok = p.Source("DB_PORT")
+			assert.Equal(t, true, ok)
+			assert.Equal(t, "environ", src.String())
+			_, ok = p.Source("TIMEOUT")
+			assert.Equal(t, false, ok)
+		})
+	})
+
+	t.Run("environ not used", func(t *testing.T) {
+		assert.WithEnviron(t, map[string]string{
+			"DB_HOST": "from-environ",
+		}, func() {
+			cfg, err := env.ParseFor[sourceConfig](env.WithSources(env.Map(nil)))
+			assert.NoError(t, err)
+			assert.Equal(t, "", cfg.DB.Host)
+		})
+	})
+
+	t.Run("dotenv", func(t *testing.T) {
+		path := writeFile(t, ".env", `
+# comment
+export DEBUG=true
+TIMEOUT = 10s # inline comment
+DB_HOST="db\tinternal"
+DB_HOSTS='a,b # not a comment'
+`)
+		src, err := env.DotEnv(path)
+		assert.NoError(t, err)
+		assert.Equal(t, "dotenv:"+path, src.String())
+
+		cfg, err := env.ParseFor[sourceConfig](env.WithSources(src))
+		assert.NoError(t, err)
+		assert.Equal(t, true, cfg.Debug)
+		assert.Equal(t, 10*time.Second, cfg.Timeout)
+		assert.Equal(t, "db\tinternal", cfg.DB.Host)
+		assert.Equal(t, []string{"a", "b # not a comment"}, cfg.DB.Hosts)
+
+		_, err = env.DotEnv(writeFile(t, ".env", "DEBUG=true\nnot a variable\n"))
+		assert.Error(t, `line 2: invalid variable: "not a variable"`, err)
+
+		_, err = env.DotEnv(writeFile(t, ".env", `DB_HOST="localhost`))
+		assert.Error(t, "line 1: unterminated quoted value", err)
+
+		_, err = env.DotEnv(filepath.Join(t.TempDir(), "missing.env"))
+		assert.Error(t, os.ErrNotExist, err)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		src, err := env.JSONFile(writeFile(t, "config.json", `{
+			"debug": true,
+			"timeout": "1m",
+			"db": {"host": "localhost", "port": 6432, "hosts": ["a", "b"]}
+		}`))
+		assert.NoError(t, err)
+
+		cfg, err := env.ParseFor[sourceConfig](env.WithSources(src))
+		assert.NoError(t, err)
+		assert.Equal(t, true, cfg.Debug)
+		assert.Equal(t, time.Minute, cfg.Timeout)
+		assert.Equal(t, "localhost", cfg.DB.Host)
+		assert.Equal(t, 6432, cfg.DB.Port)
+		assert.Equal(t, []string{"a", "b"}, cfg.DB.Hosts)
+
+		_, err = env.JSONFile(writeFile(t, "config.json", `{"db": {"hosts": [{"a": 1}]}}`))
+		assert.Error(t, "DB_HOSTS: arrays cannot nest arrays or objects", err)
+	})
+
+	t.Run("flags", func(t *testing.T) {
+		src, err := env.Flags(&sourceConfig{}, []string{"--debug", "--db-host=localhost", "-db-port", "6432", "arg"})
+		assert.NoError(t, err)
+
+		cfg, err := env.ParseFor[sourceConfig](env.WithSources(src, env.Map(map[string]string{
+			"DB_HOST": "from-map",
+			"TIMEOUT": "1m",
+		})))
+		assert.NoError(t, err)
+		assert.Equal(t, true, cfg.Debug)
+		assert.Equal(t, time.Minute, cfg.Timeout)
+		assert.Equal(t, "localhost", cfg.DB.Host)
+		assert.Equal(t, 6432, cfg.DB.Port)
+
+		_, err = env.Flags(&sourceConfig{}, []string{"--nope"})
+		assert.Error(t, "flag provided but not defined: -nope", err)
+
+		_, err = env.Flags(struct{}{}, []string{"-h"})
+		assert.Equal(t, true, errors.Is(err, flag.ErrHelp))
+	})
+}
+
+func TestFields(t *testing.T) {
+	keys := func(fields []env.Field) (keys []string) {
+		for _, f := range fields {
+			keys = append(keys, f.Key())
+		}
+		return keys
+	}
+	type recursive struct {
+		Name string `env:"NAME"`
+		Next *recursive
+	}
+	assert.Equal(t, []string{"DEBUG", "TIMEOUT", "DB_HOST", "DB_PORT", "DB_HOSTS"}, keys(env.NewParser().Fields(reflect.TypeFor[sourceConfig]())))
+	assert.Equal(t, []string{"APP_DEBUG", "APP_TIMEOUT", "APP_DB_HOST", "APP_DB_PORT", "APP_DB_HOSTS"}, keys(env.NewParser(env.RootPrefix("APP")).Fields(reflect.TypeFor[*sourceConfig]())))
+	assert.Equal(t, []string{"NAME", "NEXT_NAME"}, keys(env.NewParser().Fields(reflect.TypeFor[recursive]())))
+}
@@ -0,0 +1,131 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+
+	"go.chrisrx.dev/x/maps"
+	"go.chrisrx.dev/x/slices"
+	"go.chrisrx.dev/x/strings"
+)
+
+// ErrUnknown is returned by a [Strict] parser for variables that aren't read
+// by any field.
+var ErrUnknown = errors.New("unknown variable")
+
+// Strict is an option for [Parser] that rejects variables that aren't read by
+// any field, but start with the prefix of the parsed struct or of one of its
+// nested structs, e.g. MYAPP_DATABSE_HOST when the root prefix is MYAPP. An
+// error wrapping [ErrUnknown] is returned for each of them, which suggests
+// the closest variable that would be read. Only sources that implement
+// [Lister] are checked.
+func Strict() ParserOption {
+	return func(p *Parser) {
+		p.Strict = true
+	}
+}
+
+// addScope adds the prefix of a struct to the scopes checked by a strict
+// parser.
+func (p *Parser) addScope(prefixes []string) {
+	scope := joinPrefixes(prefixes)
+	if !p.Strict || scope == "" {
+		return
+	}
+	if p.scopes == nil {
+		p.scopes = make(map[string]struct{})
+	}
+	p.scopes[scope] = struct{}{}
+}
+
+// joinPrefixes returns the prefix of the variables of a struct.
+func joinPrefixes(prefixes []string) string {
+	return strings.ToUpper(strings.Join(slices.Filter(prefixes, func(s string) bool { return s != "" }), "_"))
+}
+
+// addKnown adds keys that are read by fields.
+func (p *Parser) addKnown(keys ...string) {
+	if !p.Strict {
+		return
+	}
+	if p.known == nil {
+		p.known = make(map[string]struct{})
+	}
+	for _, key := range keys {
+		p.known[key] = struct{}{}
+	}
+}
+
+// unknown returns an error for each variable in the scopes of the parsed
+// struct that isn't read by any field.
+func (p *Parser) unknown() Errors {
+	if !p.Strict || len(p.scopes) == 0 {
+		return nil
+	}
+	for _, init := range p.inits {
+		p.addKnown(init.key())
+	}
+	keys := make(map[string]struct{})
+	for _, src := range p.sources() {
+		if lister, ok := src.(Lister); ok {
+			for _, key := range lister.Keys() {
+				keys[key] = struct{}{}
+			}
+		}
+	}
+	known := slices.Sorted(maps.Keys(p.known))
+	var errs Errors
+	for _, key := range slices.Sorted(maps.Keys(keys)) {
+		if _, ok := p.known[key]; ok {
+			continue
+		}
+		if !p.inScope(key) {
+			continue
+		}
+		err := ErrUnknown
+		if s, ok := suggest(key, known); ok {
+			err = fmt.Errorf("%w, did you mean %s?", ErrUnknown, s)
+		}
+		errs = append(errs, &FieldError{Key: key, Err: err})
+	}
+	return errs
+}
+
+func (p *Parser) inScope(key string) bool {
+	for scope := range p.scopes {
+		if strings.HasPrefix(key, scope+"_") {
+			return true
+		}
+	}
+	return false
+}
+
+// suggest returns the known key closest to the key, if it is close enough to
+// likely be a typo.
+func suggest(key string, known []string) (string, bool) {
+	best, limit := "", len(key)/3+1
+	for _, k := range known {
+		if d := distance(key, k); d < limit {
+			best, limit = k, d
+		}
+	}
+	return best, best != ""
+}
+
+// distance returns the Levenshtein distance between two strings.
+func distance(a, b string) int {
+	prev := slices.N(len(b) + 1)
+	curr := make([]int, len(b)+1)
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
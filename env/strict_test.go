@@ -0,0 +1,59 @@
+package env_test
+
+import (
+	"testing"
+
+	"go.chrisrx.dev/x/assert"
+	"go.chrisrx.dev/x/env"
+)
+
+type strictConfig struct {
+	Debug    bool `env:"DEBUG"`
+	Database struct {
+		Host     string `env:"HOST"`
+		Port     int    `env:"PORT" default:"5432"`
+		Password string `env:"PASSWORD" secret:"true"`
+	}
+	Backends []struct {
+		Host string `env:"HOST"`
+	} `env:"BACKENDS"`
+}
+
+func TestStrict(t *testing.T) {
+	t.Run("root prefix", func(t *testing.T) {
+		password := writeFile(t, "password", "hunter2")
+		_, err := env.ParseFor[strictConfig](env.Strict(), env.RootPrefix("MYAPP"), env.WithSources(env.Map(map[string]string{
+			"MYAPP_DEBUG":                  "true",
+			"MYAPP_DATABSE_HOST":           "localhost",
+			"MYAPP_DATABASE_PROT":          "5432",
+			"MYAPP_BACKENDS_0_HOST":        "a.local",
+			"MYAPP_BACKENDS_1_HSOT":        "b.local",
+			"MYAPP_SOMETHING_ELSE":         "x",
+			"OTHERAPP_DATABSE_HOST":        "ignored",
+			"MYAPP_DATABASE_PASSWORD_FILE": password,
+		})))
+		assert.Error(t, env.ErrUnknown, err)
+		assert.Equal(t, `env: 4 errors:
+	MYAPP_BACKENDS_1_HSOT: unknown variable, did you mean MYAPP_BACKENDS_0_HOST?
+	MYAPP_DATABASE_PROT: unknown variable, did you mean MYAPP_DATABASE_PORT?
+	MYAPP_DATABSE_HOST: unknown variable, did you mean MYAPP_DATABASE_HOST?
+	MYAPP_SOMETHING_ELSE: unknown variable`, err.Error())
+	})
+
+	t.Run("auto prefix", func(t *testing.T) {
+		_, err := env.ParseFor[strictConfig](env.Strict(), env.WithSources(env.Map(map[string]string{
+			"DEBUG":         "true",
+			"DATABASE_HOST": "localhost",
+			"DATABASE_HOTS": "localhost",
+			"DEBGU":         "ignored",
+		})))
+		assert.Equal(t, "DATABASE_HOTS: unknown variable, did you mean DATABASE_HOST?", err.Error())
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		_, err := env.ParseFor[strictConfig](env.RootPrefix("MYAPP"), env.WithSources(env.Map(map[string]string{
+			"MYAPP_DATABSE_HOST": "localhost",
+		})))
+		assert.NoError(t, err)
+	})
+}
@@ -24,6 +24,14 @@ type Config struct {
 	MaxPoolConns int `env:"MAX_POOL_CONNS" required:"false"`
 }
 
+// Validate checks that the pool size limits don't conflict.
+func (c Config) Validate() error {
+	if c.MaxPoolConns != 0 && c.MinPoolConns > c.MaxPoolConns {
+		return fmt.Errorf("min pool conns (%d) is greater than max pool conns (%d)", c.MinPoolConns, c.MaxPoolConns)
+	}
+	return nil
+}
+
 func (c Config) String() string {
 	v := make(url.Values)
 	v.Set("sslmode", c.SSLMode.String())
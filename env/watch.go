@@ -0,0 +1,287 @@
+package env
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	xcontext "go.chrisrx.dev/x/context"
+	"go.chrisrx.dev/x/internal/reflectx"
+	"go.chrisrx.dev/x/slices"
+	"go.chrisrx.dev/x/strings"
+	"go.chrisrx.dev/x/structs"
+)
+
+// ReloadOn is an option for [Watch] that reloads when the process receives
+// any of the signals, e.g. syscall.SIGHUP.
+func ReloadOn(signals ...os.Signal) ParserOption {
+	return func(p *Parser) {
+		p.ReloadSignals = append(p.ReloadSignals, signals...)
+	}
+}
+
+// ReloadEvery is an option for [Watch] that reloads at an interval. This can
+// be used to pick up changes to file sources like [DotEnv], since they are
+// read again on every reload.
+func ReloadEvery(d time.Duration) ParserOption {
+	return func(p *Parser) {
+		p.ReloadInterval = d
+	}
+}
+
+// Watcher holds a config that is reloaded while a service is running. It is
+// created with [Watch].
+type Watcher[T any] struct {
+	p  *Parser
+	ch chan T
+
+	mu      sync.Mutex
+	current T
+	err     error
+	closed  bool
+}
+
+// Watch parses the config T, then keeps parsing it again when the process
+// receives one of the signals set with [ReloadOn], or at the interval set with
+// [ReloadEvery], until the context is done. [Watcher.Reload] can also be used
+// to reload at any time.
+//
+// Only fields with the `reload:"true"` tag are changed by a reload, and
+// all nested fields of a struct with the tag. Every field is parsed and
+// validated on reload the same as the first time, and if there are any errors
+// the previous config stays in force. Since a reload combines reloaded fields
+// with fields from the first parse, the validate tags and Validate methods
+// are also checked for the combined config before it is used. Setup methods
+// are only called on the first parse.
+//
+// Reload signals are handled with [xcontext.OnSignal], so passing a
+// [xcontext.ShutdownContext] as ctx stops the watcher on shutdown.
+//
+//	w, err := env.Watch[Config](ctx, env.WithSources(dotenv), env.ReloadOn(syscall.SIGHUP))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for cfg := range w.Changes() {
+//		log.SetLevel(cfg.LogLevel)
+//	}
+func Watch[T any](ctx context.Context, opts ...ParserOption) (*Watcher[T], error) {
+	w := &Watcher[T]{
+		p:  NewParser(opts...),
+		ch: make(chan T, 1),
+	}
+	if err := w.p.Parse(&w.current); err != nil {
+		return nil, err
+	}
+	stop := xcontext.OnSignal(ctx, func(os.Signal) {
+		_ = w.Reload()
+	}, w.p.ReloadSignals...)
+	var ticker *time.Ticker
+	var tick <-chan time.Time
+	if w.p.ReloadInterval > 0 {
+		ticker = time.NewTicker(w.p.ReloadInterval)
+		tick = ticker.C
+	}
+	go func() {
+		defer func() {
+			stop()
+			if ticker != nil {
+				ticker.Stop()
+			}
+			w.mu.Lock()
+			defer w.mu.Unlock()
+			w.closed = true
+			close(w.ch)
+		}()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick:
+			}
+			_ = w.Reload()
+		}
+	}()
+	return w, nil
+}
+
+// Get returns the current config.
+func (w *Watcher[T]) Get() T {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.current
+}
+
+// Changes returns a channel that receives the config whenever a reload changes
+// it. Only the latest config is kept if it isn't received before the next
+// change. The channel is closed when the context passed to [Watch] is done.
+func (w *Watcher[T]) Changes() <-chan T {
+	return w.ch
+}
+
+// Err returns the error of the last reload, or nil if it succeeded.
+func (w *Watcher[T]) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// Reload reads the sources again and updates the fields of the config with the
+// `reload:"true"` tag. Sources that implement [Reloader] are reloaded first.
+// The config is left unchanged if an error is returned.
+func (w *Watcher[T]) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.err = w.reload()
+	return w.err
+}
+
+func (w *Watcher[T]) reload() error {
+	for _, src := range w.p.sources() {
+		if r, ok := src.(Reloader); ok {
+			if err := r.Reload(); err != nil {
+				return err
+			}
+		}
+	}
+	var next T
+	if err := w.p.parseStruct(reflect.ValueOf(&next).Elem()); err != nil {
+		return err
+	}
+	cfg := w.current
+	if !reloadFields(reflect.ValueOf(&cfg).Elem(), reflect.ValueOf(next)) {
+		return nil
+	}
+	if err := w.p.validateReloaded(reflect.ValueOf(&cfg).Elem()); err != nil {
+		return err
+	}
+	w.current = cfg
+	if w.closed {
+		return nil
+	}
+	// Replace a change that hasn't been received yet, so that sending never
+	// blocks.
+	select {
+	case <-w.ch:
+	default:
+	}
+	w.ch <- cfg
+	return nil
+}
+
+// reloadFields copies the fields with the reload tag from src to dst. It
+// returns whether any of the fields changed. Nested structs behind pointers
+// are copied before they are changed, since the previous config may still be
+// in use.
+func reloadFields(dst, src reflect.Value) bool {
+	var changed bool
+	for i := range dst.NumField() {
+		field := newField(dst.Type().Field(i))
+		if !field.IsExported() {
+			continue
+		}
+		d, s := dst.Field(i), src.Field(i)
+		switch {
+		case field.Reload:
+			if !reflect.DeepEqual(d.Interface(), s.Interface()) {
+				d.Set(s)
+				changed = true
+			}
+		case d.Kind() == reflect.Struct:
+			changed = reloadFields(d, s) || changed
+		case d.Kind() == reflect.Pointer && d.Type().Elem().Kind() == reflect.Struct && !d.IsNil() && !s.IsNil():
+			elem := reflect.New(d.Type().Elem())
+			elem.Elem().Set(d.Elem())
+			if reloadFields(elem.Elem(), s.Elem()) {
+				d.Set(elem)
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// validateReloaded checks the validate tags and Validate methods of a config
+// after reloaded fields are copied into it. Both parses were valid on their
+// own, but an invariant between a reloaded field and one that isn't reloaded
+// can still be broken by the combination. The same fields are checked as by
+// [Parser.Parse], see [Parser.check].
+func (p *Parser) validateReloaded(rv reflect.Value) error {
+	p.validators = nil
+	root := slices.FilterMap([]string{p.RootPrefix}, strings.ToUpper)
+	p.addValidator(rv, Field{Field: structs.Field{Name: rv.Type().Name(), Type: rv.Type()}}, joinPrefixes(root))
+	var errs Errors
+	for i, field := range fieldsOf(rv.Type(), p.RootPrefix) {
+		errs = append(errs, p.check(rv.Field(i), field)...)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	if errs := p.validate(); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// check checks a field of a config that was already parsed, following the
+// same steps as [Parser.parse] without setting any values. Only fields that
+// are parsed are checked, so a config that passed [Parser.Parse] has the same
+// rules applied when it is checked again.
+func (p *Parser) check(rv reflect.Value, field Field) Errors {
+	if !field.IsExported() && !isDeferred(rv) {
+		return nil
+	}
+
+	switch {
+	case rv.Kind() == reflect.Pointer:
+		if rv.IsNil() {
+			return nil
+		}
+		return p.check(rv.Elem(), field)
+	case structs.HasConversion(rv), structs.IsWellKnown(rv):
+		return p.checkParsed(rv, field)
+	case isIndexed(rv.Type()):
+		prefixes := slices.Map(p.prefixes(field), strings.ToUpper)
+		var errs Errors
+		switch rv.Kind() {
+		case reflect.Slice:
+			for i := range rv.Len() {
+				errs = append(errs, p.check(rv.Index(i), elemField(field, prefixes, strconv.Itoa(i)))...)
+			}
+		case reflect.Map:
+			for _, key := range rv.MapKeys() {
+				// Map elements aren't addressable, so they are copied for
+				// Validate methods with pointer receivers.
+				elem := reflectx.MakeAddressable(rv.MapIndex(key)).Elem()
+				index := strings.ToUpper(fmt.Sprint(key.Interface()))
+				errs = append(errs, p.check(elem, elemField(field, prefixes, index))...)
+			}
+		}
+		return errs
+	case rv.Kind() == reflect.Struct:
+		prefixes := p.prefixes(field)
+		p.addValidator(rv, field, joinPrefixes(prefixes))
+		var errs Errors
+		for i, child := range fieldsOf(rv.Type(), prefixes...) {
+			errs = append(errs, p.check(rv.Field(i), child)...)
+		}
+		return errs
+	default:
+		return p.checkParsed(rv, field)
+	}
+}
+
+// checkParsed checks a value the same as [Parser.parseSingular], skipping
+// fields that aren't parsed.
+func (p *Parser) checkParsed(rv reflect.Value, field Field) Errors {
+	if !p.RequireTagged && field.Env == "" {
+		return nil
+	}
+	if err := p.checkSingular(rv, field); err != nil {
+		return Errors{err}
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package env_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"go.chrisrx.dev/x/assert"
+	"go.chrisrx.dev/x/env"
+)
+
+type watchConfig struct {
+	Addr     string `env:"ADDR"`
+	LogLevel string `env:"LOG_LEVEL" reload:"true" validate:"LogLevel == 'debug' || LogLevel == 'info'"`
+	Limits   *struct {
+		Rate  int `env:"RATE" reload:"true"`
+		Burst int `env:"BURST"`
+	}
+}
+
+type watchPool struct {
+	MinConns int `env:"MIN_CONNS"`
+	MaxConns int `env:"MAX_CONNS" reload:"true"`
+}
+
+type watchUntagged struct {
+	LogLevel string `env:"LOG_LEVEL" reload:"true"`
+
+	// Internal isn't parsed, since it has no env tag, so its validate tag
+	// isn't checked by Parse either.
+	Internal string `validate:"nonzero"`
+}
+
+func (p watchPool) Validate() error {
+	if p.MaxConns < p.MinConns {
+		return errors.New("max conns must be at least min conns")
+	}
+	return nil
+}
+
+func TestWatch(t *testing.T) {
+	t.Run("reload", func(t *testing.T) {
+		path := writeFile(t, ".env", "ADDR=:8080\nLOG_LEVEL=info\nLIMITS_RATE=10\nLIMITS_BURST=1\n")
+		dotenv, err := env.DotEnv(path)
+		assert.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(t.Context())
+		w, err := env.Watch[watchConfig](ctx, env.WithSources(dotenv))
+		assert.NoError(t, err)
+		prev := w.Get()
+		assert.Equal(t, "info", prev.LogLevel)
+		assert.Equal(t, 10, prev.Limits.Rate)
+
+		assert.NoError(t, os.WriteFile(path, []byte("ADDR=:9090\nLOG_LEVEL=debug\nLIMITS_RATE=20\nLIMITS_BURST=2\n"), 0o644))
+		assert.NoError(t, w.Reload())
+
+		cfg := <-w.Changes()
+		assert.Equal(t, ":8080", cfg.Addr)
+		assert.Equal(t, "debug", cfg.LogLevel)
+		assert.Equal(t, 20, cfg.Limits.Rate)
+		assert.Equal(t, 1, cfg.Limits.Burst)
+		assert.Equal(t, cfg, w.Get())
+
+		// The previous config isn't changed.
+		assert.Equal(t, "info", prev.LogLevel)
+		assert.Equal(t, 10, prev.Limits.Rate)
+
+		cancel()
+		_, ok := <-w.Changes()
+		assert.Equal(t, false, ok)
+	})
+
+	t.Run("validation failed", func(t *testing.T) {
+		path := writeFile(t, ".env", "LOG_LEVEL=info\n")
+		dotenv, err := env.DotEnv(path)
+		assert.NoError(t, err)
+
+		w, err := env.Watch[watchConfig](t.Context(), env.WithSources(dotenv))
+		assert.NoError(t, err)
+
+		assert.NoError(t, os.WriteFile(path, []byte("LOG_LEVEL=trace\n"), 0o644))
+		assert.Error(t, env.ErrValidation, w.Reload())
+		assert.Error(t, env.ErrValidation, w.Err())
+		assert.Equal(t, "info", w.Get().LogLevel)
+
+		assert.NoError(t, os.WriteFile(path, []byte("LOG_LEVEL=debug\n"), 0o644))
+		assert.NoError(t, w.Reload())
+		assert.NoError(t, w.Err())
+		assert.Equal(t, "debug", w.Get().LogLevel)
+	})
+
+	t.Run("combined config invalid", func(t *testing.T) {
+		path := writeFile(t, ".env", "MIN_CONNS=5\nMAX_CONNS=10\n")
+		dotenv, err := env.DotEnv(path)
+		assert.NoError(t, err)
+
+		w, err := env.Watch[watchPool](t.Context(), env.WithSources(dotenv))
+		assert.NoError(t, err)
+
+		// The new values are valid together, but MIN_CONNS isn't reloaded, so
+		// the config would have a MaxConns below MinConns.
+		assert.NoError(t, os.WriteFile(path, []byte("MIN_CONNS=1\nMAX_CONNS=2\n"), 0o644))
+		assert.Error(t, "max conns must be at least min conns", w.Reload())
+		assert.Error(t, env.ErrValidation, w.Err())
+		assert.Equal(t, watchPool{MinConns: 5, MaxConns: 10}, w.Get())
+
+		assert.NoError(t, os.WriteFile(path, []byte("MIN_CONNS=1\nMAX_CONNS=8\n"), 0o644))
+		assert.NoError(t, w.Reload())
+		assert.Equal(t, watchPool{MinConns: 5, MaxConns: 8}, w.Get())
+	})
+
+	t.Run("untagged field", func(t *testing.T) {
+		path := writeFile(t, ".env", "LOG_LEVEL=info\n")
+		dotenv, err := env.DotEnv(path)
+		assert.NoError(t, err)
+
+		w, err := env.Watch[watchUntagged](t.Context(), env.WithSources(dotenv))
+		assert.NoError(t, err)
+
+		assert.NoError(t, os.WriteFile(path, []byte("LOG_LEVEL=debug\n"), 0o644))
+		assert.NoError(t, w.Reload())
+		assert.Equal(t, "debug", w.Get().LogLevel)
+	})
+
+	t.Run("signal", func(t *testing.T) {
+		assert.WithEnviron(t, map[string]string{"LOG_LEVEL": "info"}, func() {
+			w, err := env.Watch[watchConfig](t.Context(), env.ReloadOn(syscall.SIGHUP))
+			assert.NoError(t, err)
+
+			assert.NoError(t, os.Setenv("LOG_LEVEL", "debug"))
+			assert.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+			select {
+			case cfg := <-w.Changes():
+				assert.Equal(t, "debug", cfg.LogLevel)
+			case <-time.After(5 * time.Second):
+				t.Fatal("timed out waiting for reload")
+			}
+		})
+	})
+
+	t.Run("interval", func(t *testing.T) {
+		values := map[string]string{"LOG_LEVEL": "info"}
+		assert.WithEnviron(t, values, func() {
+			w, err := env.Watch[watchConfig](t.Context(), env.ReloadEvery(10*time.Millisecond))
+			assert.NoError(t, err)
+
+			assert.NoError(t, os.Setenv("LOG_LEVEL", "debug"))
+			select {
+			case cfg := <-w.Changes():
+				assert.Equal(t, "debug", cfg.LogLevel)
+			case <-time.After(5 * time.Second):
+				t.Fatal("timed out waiting for reload")
+			}
+		})
+	})
+}
@@ -11,16 +11,18 @@ import (
 	"math"
 	"math/rand/v2"
 	"net"
+	"net/netip"
+	"net/url"
 	"os"
 	"path/filepath"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
-	"go.chrisrx.dev/x/must"
 	"go.chrisrx.dev/x/ptr"
 )
 
@@ -32,9 +34,15 @@ func enableTesting() {
 
 var testingTime = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
 
-// TODO(ChrisRx): generate the builtins/docs
+// The comment on the line before each builtin and package member is its
+// description, which is returned by [Builtins]. The descriptions in
+// docs_gen.go and BUILTINS.md are generated from them.
+//
+//go:generate go run ./internal/gendocs -src builtins.go -gen docs_gen.go -o BUILTINS.md
 
 var builtins = map[string]reflect.Value{
+	// Length of a string, slice, array, map or channel, or 0 for any other
+	// value.
 	"len": reflect.ValueOf(func(v any) int {
 		rv := reflect.ValueOf(v)
 		if rv.Kind() == reflect.Pointer {
@@ -47,25 +55,87 @@ var builtins = map[string]reflect.Value{
 			return 0
 		}
 	}),
+
+	// Reports whether the value is non-zero and non-nil.
 	"some": reflect.ValueOf(func(v any) bool { return !ptr.IsZero(v) }),
+	// Reports whether the value is zero or nil.
 	"none": reflect.ValueOf(func(v any) bool { return ptr.IsZero(v) }),
 
+	// conditionals
+
+	// Returns the second argument if the predicate is true, otherwise the
+	// third. Both arguments are evaluated.
+	"cond": reflect.ValueOf(func(pred bool, a, b any) any {
+		if pred {
+			return a
+		}
+		return b
+	}),
+
+	// Returns the first non-zero value, or the last value if all are zero.
+	"coalesce": reflect.ValueOf(func(values ...any) any {
+		for _, v := range values {
+			if !ptr.IsZero(v) {
+				return v
+			}
+		}
+		if len(values) == 0 {
+			return nil
+		}
+		return values[len(values)-1]
+	}),
+
+	// collections
+
+	// Reports whether the value is an element of a slice or array, a key of a
+	// map or a substring of a string. Numbers are compared by value.
+	"in": reflect.ValueOf(inFunc),
+	// Same as in, with the arguments reversed.
+	"contains": reflect.ValueOf(func(col, x any) (bool, error) { return inFunc(x, col) }),
+	// Applies a function to each element of a slice, array or map, returning a
+	// slice of the results.
+	"map": reflect.ValueOf(mapFunc),
+	// Returns the elements of a slice, array or map for which the function
+	// returns true. Maps are filtered into a map of the same type.
+	"filter": reflect.ValueOf(filterFunc),
+	// Reports whether the function returns true for every element of a slice,
+	// array or map.
+	"all": reflect.ValueOf(allFunc),
+	// Reports whether the function returns true for any element of a slice,
+	// array or map.
+	"any": reflect.ValueOf(anyFunc),
+	// Number of elements of a slice, array or map for which the function
+	// returns true.
+	"count": reflect.ValueOf(countFunc),
+	// Sum of the numeric elements of a slice, array or map, as an int64, uint64
+	// or float64.
+	"sum": reflect.ValueOf(sumFunc),
+	// Sorted keys of a map.
+	"keys": reflect.ValueOf(keysFunc),
+	// Values of a map, sorted by key.
+	"values": reflect.ValueOf(valuesFunc),
+
 	// basic type casts
-	"int": reflect.ValueOf(func(v any) int {
+
+	// Converts a number or a decimal string to an int.
+	"int": reflect.ValueOf(func(v any) (int, error) {
 		rv := reflect.ValueOf(v)
 		switch rv.Kind() {
 		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			return int(rv.Int())
+			return int(rv.Int()), nil
 		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			return int(rv.Uint())
+			return int(rv.Uint()), nil
 		case reflect.Float32, reflect.Float64:
-			return int(rv.Float())
+			return int(rv.Float()), nil
 		case reflect.String:
-			return int(must.Get0(strconv.ParseInt(rv.String(), 10, 64)))
+			i, err := strconv.ParseInt(rv.String(), 10, 64)
+			return int(i), err
 		default:
-			return 0
+			return 0, nil
 		}
 	}),
+
+	// Converts a number to a float64.
 	"float": reflect.ValueOf(func(v any) float64 {
 		rv := reflect.ValueOf(v)
 		switch rv.Kind() {
@@ -79,11 +149,18 @@ var builtins = map[string]reflect.Value{
 			return float64(0)
 		}
 	}),
+
+	// Formats any value as a string.
 	"string": reflect.ValueOf(func(v any) string { return fmt.Sprint(v) }),
 
 	// math
+
+	// Smaller of two numbers.
 	"min": reflect.ValueOf(math.Min),
+	// Larger of two numbers.
 	"max": reflect.ValueOf(math.Max),
+	// Random int. With one argument n the result is in [0, n), with two
+	// arguments min and max it is in [min, max).
 	"rand": reflect.ValueOf(func(args ...any) int {
 		switch len(args) {
 		case 1:
@@ -96,41 +173,73 @@ var builtins = map[string]reflect.Value{
 			return rand.Int()
 		}
 	}),
+
+	// Random float64 in [0.0, 1.0).
 	"random": reflect.ValueOf(rand.Float64),
 
 	// strings
+
+	// Reports whether the string begins with the prefix.
 	"startswith": reflect.ValueOf(strings.HasPrefix),
-	"endswith":   reflect.ValueOf(strings.HasSuffix),
-	"trim":       reflect.ValueOf(strings.Trim),
-	"upper":      reflect.ValueOf(strings.ToUpper),
-	"lower":      reflect.ValueOf(strings.ToLower),
-	"split":      reflect.ValueOf(strings.Split),
-	"atoi":       reflect.ValueOf(func(s string) int { return must.Get0(strconv.Atoi(s)) }),
-	"itoa":       reflect.ValueOf(strconv.Itoa),
-	"quote":      reflect.ValueOf(strconv.Quote),
-	"unquote":    reflect.ValueOf(func(s string) string { return must.Get0(strconv.Unquote(s)) }),
+	// Reports whether the string ends with the suffix.
+	"endswith": reflect.ValueOf(strings.HasSuffix),
+	// Removes leading and trailing characters contained in the cutset.
+	"trim": reflect.ValueOf(strings.Trim),
+	// Converts a string to upper case.
+	"upper": reflect.ValueOf(strings.ToUpper),
+	// Converts a string to lower case.
+	"lower": reflect.ValueOf(strings.ToLower),
+	// Splits a string around each occurrence of the separator.
+	"split": reflect.ValueOf(strings.Split),
+	// Parses a decimal string as an int.
+	"atoi": reflect.ValueOf(strconv.Atoi),
+	// Formats an int as a decimal string.
+	"itoa": reflect.ValueOf(strconv.Itoa),
+	// Returns a double-quoted Go string literal.
+	"quote": reflect.ValueOf(strconv.Quote),
+	// Interprets a quoted Go string literal.
+	"unquote": reflect.ValueOf(strconv.Unquote),
 
 	// fmt
-	"print":    reflect.ValueOf(fmt.Print),
-	"printf":   reflect.ValueOf(fmt.Printf),
-	"println":  reflect.ValueOf(fmt.Println),
-	"sprint":   reflect.ValueOf(fmt.Sprint),
-	"sprintf":  reflect.ValueOf(fmt.Sprintf),
+
+	// Formats the arguments like fmt.Print and writes them to standard output.
+	"print": reflect.ValueOf(fmt.Print),
+	// Formats the arguments like fmt.Printf and writes them to standard output.
+	"printf": reflect.ValueOf(fmt.Printf),
+	// Formats the arguments like fmt.Println and writes them to standard
+	// output.
+	"println": reflect.ValueOf(fmt.Println),
+	// Formats the arguments like fmt.Sprint.
+	"sprint": reflect.ValueOf(fmt.Sprint),
+	// Formats the arguments like fmt.Sprintf.
+	"sprintf": reflect.ValueOf(fmt.Sprintf),
+	// Formats the arguments like fmt.Sprintln.
 	"sprintln": reflect.ValueOf(fmt.Sprintln),
 
 	// os
-	"getwd":    reflect.ValueOf(func() string { return must.Get0(os.Getwd()) }),
-	"tempdir":  reflect.ValueOf(os.TempDir),
+
+	// Current working directory.
+	"getwd": reflect.ValueOf(os.Getwd),
+	// Default directory for temporary files.
+	"tempdir": reflect.ValueOf(os.TempDir),
+	// Joins path elements with the OS specific separator.
 	"joinpath": reflect.ValueOf(filepath.Join),
-	"getenv":   reflect.ValueOf(os.Getenv),
+	// Value of an environment variable, or an empty string if it isn't set.
+	"getenv": reflect.ValueOf(os.Getenv),
 
 	// time
+
+	// Current local time.
 	"now": reflect.ValueOf(func() time.Time {
 		if isTesting.Load() {
 			return time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
 		}
 		return time.Now()
 	}),
+
+	// Time for year, month, day, hour, min, sec, nsec and location. Arguments
+	// that are left out are zero, except for the month which is January and the
+	// location which is UTC.
 	"date": reflect.ValueOf(func(args ...any) time.Time {
 		return time.Date(
 			take[int](args, 0),            // year
@@ -143,13 +252,24 @@ var builtins = map[string]reflect.Value{
 			takeOr(args, 7, time.UTC),     // loc
 		)
 	}),
-	"duration": reflect.ValueOf(func(s string) time.Duration {
-		return must.Get0(time.ParseDuration(s))
-	}),
+
+	// Parses a duration, e.g. "1h30m". Days ("d") and weeks ("w") are also
+	// accepted.
+	"duration": reflect.ValueOf(parseDuration),
+
+	// units
+
+	// Parses a byte size with an optional SI ("MB") or IEC ("MiB") unit, e.g.
+	// "512MiB".
+	"parse_bytes": reflect.ValueOf(parseBytes),
 
 	// net
+
+	// Parses an IEEE 802 MAC address.
 	"parse_mac": reflect.ValueOf(net.ParseMAC),
-	"parse_ip":  reflect.ValueOf(net.ParseIP),
+	// Parses an IPv4 or IPv6 address, returning nil if it is invalid.
+	"parse_ip": reflect.ValueOf(net.ParseIP),
+	// Splits a "host:port" address into its host and port.
 	"split_addr": reflect.ValueOf(func(s string) struct {
 		Host string
 		Port int
@@ -163,16 +283,98 @@ var builtins = map[string]reflect.Value{
 		return addr{Host: host, Port: i}
 	}),
 
+	// Parses a CIDR prefix, e.g. "10.0.0.0/8".
+	"parse_cidr": reflect.ValueOf(netip.ParsePrefix),
+	// Reports whether an IP address is within a CIDR prefix.
+	"cidr_contains": reflect.ValueOf(func(cidr, ip string) (bool, error) {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return false, err
+		}
+		addr, err := netip.ParseAddr(ip)
+		if err != nil {
+			return false, err
+		}
+		return prefix.Contains(addr.Unmap()), nil
+	}),
+
+	// regexp
+
+	// Reports whether the string contains a match of the regular expression.
+	"match": reflect.ValueOf(func(pattern, s string) (bool, error) {
+		re, err := compileRegexp(pattern)
+		if err != nil {
+			return false, err
+		}
+		return re.MatchString(s), nil
+	}),
+
+	// Leftmost match of the regular expression in the string.
+	"find": reflect.ValueOf(func(pattern, s string) (string, error) {
+		re, err := compileRegexp(pattern)
+		if err != nil {
+			return "", err
+		}
+		return re.FindString(s), nil
+	}),
+
+	// All matches of the regular expression in the string.
+	"find_all": reflect.ValueOf(func(pattern, s string) ([]string, error) {
+		re, err := compileRegexp(pattern)
+		if err != nil {
+			return nil, err
+		}
+		return re.FindAllString(s, -1), nil
+	}),
+
+	// Replaces matches of the regular expression with the replacement, which
+	// can reference groups with $1.
+	"replace_all": reflect.ValueOf(func(pattern, s, repl string) (string, error) {
+		re, err := compileRegexp(pattern)
+		if err != nil {
+			return "", err
+		}
+		return re.ReplaceAllString(s, repl), nil
+	}),
+
+	// semver
+
+	// Parses a semantic version. A leading "v" is allowed and the minor and
+	// patch versions can be left out. The result has a Compare method that
+	// compares it with another version.
+	"semver": reflect.ValueOf(parseSemver),
+	// Compares two semantic versions, returning -1, 0 or +1.
+	"semver_compare": reflect.ValueOf(func(a, b string) (int, error) {
+		v, err := parseSemver(a)
+		if err != nil {
+			return 0, err
+		}
+		return v.Compare(b)
+	}),
+
+	// url
+
+	// Parses a URL.
+	"parse_url": reflect.ValueOf(url.Parse),
+
 	// hash
+
+	// HMAC-SHA256 hex digest of the data using the key.
 	"hmac": reflect.ValueOf(func(args ...any) string {
 		return fmt.Sprintf("%x", hmac.New(sha256.New, take[[]byte](args, 0)).Sum(take[[]byte](args, 1)))
 	}),
+
+	// MD5 hex digest.
 	"md5": reflect.ValueOf(func(input any) string {
 		return fmt.Sprintf("%x", md5.Sum(convert[[]byte](input)))
 	}),
+
+	// SHA-1 hex digest.
 	"sha1": reflect.ValueOf(func(input any) string {
 		return fmt.Sprintf("%x", sha1.Sum(convert[[]byte](input)))
 	}),
+
+	// SHA-256 hex digest.
 	"sha256": reflect.ValueOf(func(input any) string {
 		return fmt.Sprintf("%x", sha256.Sum256(convert[[]byte](input)))
 	}),
@@ -182,54 +384,88 @@ var builtins = map[string]reflect.Value{
 var packages = sync.OnceValue(func() map[string]map[string]reflect.Value {
 	return map[string]map[string]reflect.Value{
 		"fmt": {
-			"Print":   reflect.ValueOf(fmt.Print),
-			"Printf":  reflect.ValueOf(fmt.Printf),
+			// Same as print.
+			"Print": reflect.ValueOf(fmt.Print),
+			// Same as printf.
+			"Printf": reflect.ValueOf(fmt.Printf),
+			// Same as println.
 			"Println": reflect.ValueOf(fmt.Println),
-			"Sprint":  reflect.ValueOf(fmt.Sprint),
+			// Same as sprint.
+			"Sprint": reflect.ValueOf(fmt.Sprint),
+			// Same as sprintf.
 			"Sprintf": reflect.ValueOf(fmt.Sprintf),
 		},
 		"math": {
-			"Abs":   reflect.ValueOf(math.Abs),
-			"Acos":  reflect.ValueOf(math.Acos),
-			"Asin":  reflect.ValueOf(math.Asin),
-			"Atan":  reflect.ValueOf(math.Atan),
-			"Ceil":  reflect.ValueOf(math.Ceil),
-			"Cos":   reflect.ValueOf(math.Cos),
-			"Exp":   reflect.ValueOf(math.Exp),
-			"Log":   reflect.ValueOf(math.Log),
-			"Max":   reflect.ValueOf(math.Max),
-			"Min":   reflect.ValueOf(math.Min),
+			// Absolute value.
+			"Abs": reflect.ValueOf(math.Abs),
+			// Arccosine, in radians.
+			"Acos": reflect.ValueOf(math.Acos),
+			// Arcsine, in radians.
+			"Asin": reflect.ValueOf(math.Asin),
+			// Arctangent, in radians.
+			"Atan": reflect.ValueOf(math.Atan),
+			// Least integer value greater than or equal to the argument.
+			"Ceil": reflect.ValueOf(math.Ceil),
+			// Cosine of the radian argument.
+			"Cos": reflect.ValueOf(math.Cos),
+			// e**x, the base-e exponential.
+			"Exp": reflect.ValueOf(math.Exp),
+			// Natural logarithm.
+			"Log": reflect.ValueOf(math.Log),
+			// Larger of two numbers.
+			"Max": reflect.ValueOf(math.Max),
+			// Smaller of two numbers.
+			"Min": reflect.ValueOf(math.Min),
+			// Nearest integer, rounding half away from zero.
 			"Round": reflect.ValueOf(math.Round),
-			"Sin":   reflect.ValueOf(math.Sin),
-			"Tan":   reflect.ValueOf(math.Tan),
+			// Sine of the radian argument.
+			"Sin": reflect.ValueOf(math.Sin),
+			// Tangent of the radian argument.
+			"Tan": reflect.ValueOf(math.Tan),
 		},
 		"time": {
-			"Time":        reflect.ValueOf(time.Time{}),
-			"Local":       reflect.ValueOf(time.Local),
-			"UTC":         reflect.ValueOf(time.UTC),
-			"Date":        reflect.ValueOf(time.Date),
-			"Now":         reflect.ValueOf(time.Now),
-			"Nanosecond":  reflect.ValueOf(time.Nanosecond),
+			// The time.Time type, e.g. time.Time{} is the zero time.
+			"Time": reflect.ValueOf(time.Time{}),
+			// The system's local time zone.
+			"Local": reflect.ValueOf(time.Local),
+			// Coordinated Universal Time.
+			"UTC": reflect.ValueOf(time.UTC),
+			// Same as Go's time.Date.
+			"Date": reflect.ValueOf(time.Date),
+			// Current local time.
+			"Now": reflect.ValueOf(time.Now),
+			// A nanosecond duration.
+			"Nanosecond": reflect.ValueOf(time.Nanosecond),
+			// A millisecond duration.
 			"Millisecond": reflect.ValueOf(time.Millisecond),
-			"Second":      reflect.ValueOf(time.Second),
-			"Minute":      reflect.ValueOf(time.Minute),
-			"Hour":        reflect.ValueOf(time.Hour),
-			"Duration":    reflect.ValueOf(time.Duration(0)),
+			// A second duration.
+			"Second": reflect.ValueOf(time.Second),
+			// A minute duration.
+			"Minute": reflect.ValueOf(time.Minute),
+			// An hour duration.
+			"Hour": reflect.ValueOf(time.Hour),
+			// The time.Duration type, e.g. time.Duration(5) is 5ns.
+			"Duration": reflect.ValueOf(time.Duration(0)),
 		},
 		"net": {
-			"ParseIP":   reflect.ValueOf(net.ParseIP),
-			"ParseCIDR": reflect.ValueOf(net.ParseCIDR),
+			// Same as parse_ip.
+			"ParseIP": reflect.ValueOf(net.ParseIP),
 		},
 		"json": {
-			"Encode": reflect.ValueOf(func(v any) string {
-				return string(must.Get0(json.Marshal(v)))
+			// Encodes a value as JSON.
+			"Encode": reflect.ValueOf(func(v any) (string, error) {
+				data, err := json.Marshal(v)
+				return string(data), err
 			}),
 		},
 		"base64": {
+			// Standard base64 decoding of a string.
 			"Decode": reflect.ValueOf(func(s string) string {
 				data, _ := base64.StdEncoding.DecodeString(s)
 				return string(data)
 			}),
+
+			// Standard base64 encoding of a string or []byte.
 			"Encode": reflect.ValueOf(func(input any) string {
 				switch input := input.(type) {
 				case []byte:
@@ -244,6 +480,118 @@ var packages = sync.OnceValue(func() map[string]map[string]reflect.Value {
 	}
 })
 
+// maxRegexps is the maximum number of compiled regular expressions that are
+// cached. Patterns are usually literals in validation expressions, so the
+// cache is only a safeguard against expressions that build patterns
+// dynamically.
+const maxRegexps = 1024
+
+var regexps struct {
+	sync.RWMutex
+	cache map[string]*regexp.Regexp
+}
+
+// compileRegexp compiles a regular expression, caching the result so that
+// expressions evaluated many times only compile a pattern once.
+func compileRegexp(pattern string) (*regexp.Regexp, error) {
+	regexps.RLock()
+	re, ok := regexps.cache[pattern]
+	regexps.RUnlock()
+	if ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	regexps.Lock()
+	defer regexps.Unlock()
+	if regexps.cache == nil {
+		regexps.cache = make(map[string]*regexp.Regexp)
+	}
+	if len(regexps.cache) < maxRegexps {
+		regexps.cache[pattern] = re
+	}
+	return re, nil
+}
+
+var dayUnits = regexp.MustCompile(`([0-9]*(?:\.[0-9]*)?)([dw])`)
+
+// parseDuration parses a duration like [time.ParseDuration], but also accepts
+// days ("d") and weeks ("w"), e.g. "7d" or "1w2d12h". Days are always 24
+// hours.
+func parseDuration(s string) (time.Duration, error) {
+	if !dayUnits.MatchString(s) {
+		return time.ParseDuration(s)
+	}
+	var err error
+	hours := dayUnits.ReplaceAllStringFunc(s, func(m string) string {
+		sub := dayUnits.FindStringSubmatch(m)
+		f, perr := strconv.ParseFloat(sub[1], 64)
+		if perr != nil {
+			err = perr
+			return m
+		}
+		if sub[2] == "w" {
+			f *= 7
+		}
+		return strconv.FormatFloat(f*24, 'f', -1, 64) + "h"
+	})
+	if err != nil {
+		return 0, fmt.Errorf("time: invalid duration %q", s)
+	}
+	d, err := time.ParseDuration(hours)
+	if err != nil {
+		return 0, fmt.Errorf("time: invalid duration %q", s)
+	}
+	return d, nil
+}
+
+var byteUnits = map[string]float64{
+	"":    1,
+	"b":   1,
+	"k":   1e3,
+	"kb":  1e3,
+	"kib": 1 << 10,
+	"m":   1e6,
+	"mb":  1e6,
+	"mib": 1 << 20,
+	"g":   1e9,
+	"gb":  1e9,
+	"gib": 1 << 30,
+	"t":   1e12,
+	"tb":  1e12,
+	"tib": 1 << 40,
+	"p":   1e15,
+	"pb":  1e15,
+	"pib": 1 << 50,
+}
+
+// parseBytes parses a byte size with an optional SI ("MB") or IEC ("MiB")
+// unit, e.g. "512MiB" or "1.5GB". Units are case-insensitive.
+func parseBytes(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(s)
+	}
+	n, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid byte size: %q", s)
+	}
+	unit, ok := byteUnits[strings.ToLower(strings.TrimSpace(s[i:]))]
+	if !ok {
+		return 0, fmt.Errorf("invalid byte size unit: %q", s)
+	}
+	size := n * unit
+	if size >= math.MaxInt64 {
+		return 0, fmt.Errorf("byte size out of range: %q", s)
+	}
+	return int64(size), nil
+}
+
 func convert[T any](v any) T {
 	rv := reflect.ValueOf(v)
 	rt := reflect.TypeFor[T]()
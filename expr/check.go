@@ -0,0 +1,496 @@
+package expr
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"reflect"
+	"time"
+)
+
+// checker statically checks an expression using the same rules as the
+// evaluator. Types that cannot be known until evaluation, like values held by
+// an interface, are represented by a nil [reflect.Type] and are not checked.
+type checker struct {
+	e *Expr
+
+	// vars are the declared variables referenced by the expression.
+	vars map[string]reflect.Type
+
+	// builtins are the builtins and package members referenced by the
+	// expression.
+	builtins map[string]struct{}
+
+	// scope are the parameters of the function literal being checked.
+	scope map[string]reflect.Type
+}
+
+var (
+	boolType     = reflect.TypeFor[bool]()
+	int64Type    = reflect.TypeFor[int64]()
+	uint64Type   = reflect.TypeFor[uint64]()
+	float64Type  = reflect.TypeFor[float64]()
+	stringType   = reflect.TypeFor[string]()
+	uintptrType  = reflect.TypeFor[uintptr]()
+	durationType = reflect.TypeFor[time.Duration]()
+	timeType     = reflect.TypeFor[time.Time]()
+)
+
+// dynamic returns nil for interface types, since the type of the underlying
+// value can only be known during evaluation.
+func dynamic(t reflect.Type) reflect.Type {
+	if t == nil || t.Kind() == reflect.Interface {
+		return nil
+	}
+	return t
+}
+
+func (c *checker) check(expr ast.Expr) (reflect.Type, error) {
+	t, err := c.checkExpr(expr)
+	if err != nil {
+		return nil, c.e.errorAt(expr, err)
+	}
+	return t, nil
+}
+
+func (c *checker) checkExpr(expr ast.Expr) (reflect.Type, error) {
+	switch expr := expr.(type) {
+	case *ast.ParenExpr:
+		return c.check(expr.X)
+	case *ast.StarExpr:
+		return c.check(expr.X)
+	case *ast.CallExpr:
+		return c.checkCallExpr(expr)
+	case *ast.SelectorExpr:
+		return c.checkSelectorExpr(expr)
+	case *ast.Ident:
+		if t, ok := c.scope[expr.Name]; ok {
+			return dynamic(t), nil
+		}
+		if t, ok := c.e.types[expr.Name]; ok {
+			c.vars[expr.Name] = t
+			return dynamic(t), nil
+		}
+		if v, ok := c.e.builtin(expr.Name); ok {
+			c.builtins[expr.Name] = struct{}{}
+			return dynamic(v.Type()), nil
+		}
+		if _, ok := c.e.packages[expr.Name]; ok {
+			return nil, fmt.Errorf("use of package %s without selector", expr.Name)
+		}
+		if capability, ok := c.e.denied[expr.Name]; ok {
+			return nil, &CapabilityError{Name: expr.Name, Capability: capability}
+		}
+		return nil, fmt.Errorf("%w: %v", errUndefined, expr.Name)
+	case *ast.BasicLit:
+		v, err := c.e.evalBasicLit(expr)
+		if err != nil {
+			return nil, err
+		}
+		return v.Type(), nil
+	case *ast.CompositeLit:
+		return c.checkCompositeLit(expr)
+	case *ast.UnaryExpr:
+		return c.checkUnaryExpr(expr)
+	case *ast.BinaryExpr:
+		return c.checkBinaryExpr(expr)
+	case *ast.IndexExpr:
+		return c.checkIndexExpr(expr)
+	case *ast.FuncLit:
+		return c.checkFuncLit(expr)
+	}
+	return nil, fmt.Errorf("unsupported ast.Expr: %T", expr)
+}
+
+// pkg returns the name of the package an identifier refers to, if the
+// identifier isn't shadowed by a variable or builtin.
+func (c *checker) pkg(expr ast.Expr) (string, bool) {
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+	if _, ok := c.scope[ident.Name]; ok {
+		return "", false
+	}
+	if _, ok := c.e.types[ident.Name]; ok {
+		return "", false
+	}
+	if _, ok := c.e.builtin(ident.Name); ok {
+		return "", false
+	}
+	if _, ok := c.e.packages[ident.Name]; ok {
+		return ident.Name, true
+	}
+	return "", false
+}
+
+func (c *checker) checkBinaryExpr(expr *ast.BinaryExpr) (reflect.Type, error) {
+	lhs, err := c.check(expr.X)
+	if err != nil {
+		return nil, err
+	}
+	rhs, err := c.check(expr.Y)
+	if err != nil {
+		return nil, err
+	}
+	isComparison := func() bool {
+		switch expr.Op {
+		case token.EQL, token.NEQ, token.LSS, token.GTR, token.LEQ, token.GEQ, token.LAND, token.LOR:
+			return true
+		default:
+			return false
+		}
+	}
+	if lhs == nil || rhs == nil {
+		if isComparison() {
+			return boolType, nil
+		}
+		return nil, nil
+	}
+
+	isTime := func(t reflect.Type) bool {
+		return t == timeType || t == reflect.PointerTo(timeType)
+	}
+	switch {
+	case lhs == durationType && isTime(rhs), isTime(lhs) && rhs == durationType:
+		switch expr.Op {
+		case token.ADD, token.SUB:
+			return timeType, nil
+		}
+	}
+
+	if lhs != rhs && !convertible(lhs, rhs) {
+		return nil, fmt.Errorf("invalid operation: operator %s on incompatible types: %v <> %v", expr.Op, lhs, rhs)
+	}
+
+	// The kind of the operands after conversion, see [Expr.evalBinaryExpr].
+	var kind reflect.Kind
+	switch {
+	case isFloat(lhs) || isFloat(rhs):
+		kind = reflect.Float64
+	case (isInt(lhs) && isUint(rhs)) || (isUint(lhs) && isInt(rhs)):
+		kind = rhs.Kind()
+		if lhs.Bits() > rhs.Bits() {
+			kind = lhs.Kind()
+		}
+	default:
+		kind = rhs.Kind()
+	}
+
+	var ops []token.Token
+	var result reflect.Type
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		ops = []token.Token{token.ADD, token.SUB, token.MUL, token.QUO, token.REM, token.AND, token.OR, token.XOR, token.SHL, token.SHR}
+		result = int64Type
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		ops = []token.Token{token.ADD, token.SUB, token.MUL, token.QUO, token.REM, token.AND, token.OR, token.XOR, token.SHL, token.SHR}
+		result = uint64Type
+	case reflect.Float32, reflect.Float64:
+		ops = []token.Token{token.ADD, token.SUB, token.MUL, token.QUO}
+		result = float64Type
+	case reflect.String:
+		ops = []token.Token{token.ADD, token.SUB}
+		result = stringType
+	case reflect.Bool:
+		ops = []token.Token{token.LAND, token.LOR}
+		result = boolType
+	}
+	switch expr.Op {
+	case token.EQL, token.NEQ:
+		if result != nil {
+			return boolType, nil
+		}
+	case token.LSS, token.GTR, token.LEQ, token.GEQ:
+		if result != nil && result != boolType {
+			return boolType, nil
+		}
+	default:
+		for _, op := range ops {
+			if op == expr.Op {
+				return result, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("operator %s not defined for %v", expr.Op, lhs)
+}
+
+func (c *checker) checkCallExpr(expr *ast.CallExpr) (reflect.Type, error) {
+	fn, err := c.check(expr.Fun)
+	if err != nil {
+		return nil, err
+	}
+	args := make([]reflect.Type, len(expr.Args))
+	for i, arg := range expr.Args {
+		args[i], err = c.check(arg)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if fn == nil {
+		return nil, nil
+	}
+	if fn.Kind() != reflect.Func {
+		// Calling a value that isn't a function is a type conversion.
+		if len(args) > 0 && args[0] != nil && !args[0].ConvertibleTo(fn) {
+			return nil, fmt.Errorf("cannot convert: %v -> %v", args[0], fn)
+		}
+		return fn, nil
+	}
+	result := func() (reflect.Type, error) {
+		if fn.NumOut() == 0 {
+			return nil, fmt.Errorf("func %s() (no value) used as value", funcName(expr))
+		}
+		if err := checkResults(fn); err != nil {
+			return nil, fmt.Errorf("func %s() %w", funcName(expr), err)
+		}
+		return dynamic(fn.Out(0)), nil
+	}
+	if fn.IsVariadic() && len(args) >= fn.NumIn()-1 {
+		// The variadic parameter is optional, so only the parameters before
+		// it are required.
+		for i, arg := range args {
+			t := paramType(fn, i)
+			if arg != nil && !arg.AssignableTo(t) && !convertible(arg, t) {
+				return nil, fmt.Errorf("cannot convert: %v -> %v", arg, t)
+			}
+		}
+		return result()
+	}
+
+	if len(args) == 0 && fn.NumIn() == 1 {
+		if self, ok := c.e.types["self"]; ok && convertible(self, fn.In(0)) {
+			c.vars["self"] = self
+			return result()
+		}
+	}
+	if len(args) != fn.NumIn() {
+		return nil, fmt.Errorf("func %s() takes %d args, received %d", funcName(expr), fn.NumIn(), len(args))
+	}
+	for i, arg := range args {
+		if arg != nil && !convertible(arg, fn.In(i)) {
+			return nil, fmt.Errorf("cannot convert: %v -> %v", arg, fn.In(i))
+		}
+	}
+	return result()
+}
+
+// paramType returns the type of the i-th argument of a call to a variadic
+// function, which is the element type of the variadic parameter for the
+// arguments it receives.
+func paramType(fn reflect.Type, i int) reflect.Type {
+	if i < fn.NumIn()-1 {
+		return fn.In(i)
+	}
+	return fn.In(fn.NumIn() - 1).Elem()
+}
+
+// checkResults returns an error if a function has results that would be
+// dropped by a call. A call evaluates to a single value, so only functions
+// returning a value, or a value and an error, can be called.
+func checkResults(fn reflect.Type) error {
+	if n := fn.NumOut(); n > 2 || (n == 2 && fn.Out(1) != errorType) {
+		return fmt.Errorf("returns %d values, only a value or a value and an error can be used", n)
+	}
+	return nil
+}
+
+func funcName(expr *ast.CallExpr) string {
+	switch fn := expr.Fun.(type) {
+	case *ast.Ident:
+		return fn.Name
+	case *ast.SelectorExpr:
+		return fn.Sel.Name
+	}
+	return ""
+}
+
+func (c *checker) checkCompositeLit(expr *ast.CompositeLit) (reflect.Type, error) {
+	t, err := c.check(expr.Type)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return t, nil
+	}
+	for _, elem := range expr.Elts {
+		kv, ok := elem.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key, ok := kv.Key.(*ast.Ident)
+		if !ok {
+			return nil, fmt.Errorf("invalid field name %T in struct literal", kv.Key)
+		}
+		field, ok := t.FieldByName(key.Name)
+		if !ok {
+			return nil, fmt.Errorf("unknown field %s in struct literal of type %v", key.Name, t)
+		}
+		v, err := c.check(kv.Value)
+		if err != nil {
+			return nil, err
+		}
+		if v != nil && !v.ConvertibleTo(field.Type) {
+			return nil, fmt.Errorf("cannot convert: %v -> %v", v, field.Type)
+		}
+	}
+	return t, nil
+}
+
+func (c *checker) checkSelectorExpr(expr *ast.SelectorExpr) (reflect.Type, error) {
+	if name, ok := c.pkg(expr.X); ok {
+		for _, sel := range []string{expr.Sel.Name, upper(expr.Sel.Name)} {
+			if v, ok := c.e.packages[name][sel]; ok {
+				c.builtins[name+"."+sel] = struct{}{}
+				return dynamic(v.Type()), nil
+			}
+		}
+		for _, sel := range []string{expr.Sel.Name, upper(expr.Sel.Name)} {
+			if capability, ok := c.e.denied[name+"."+sel]; ok {
+				return nil, &CapabilityError{Name: name + "." + sel, Capability: capability}
+			}
+		}
+		return nil, fmt.Errorf("cannot find object in package: %s.%s", name, expr.Sel.Name)
+	}
+	x, err := c.check(expr.X)
+	if err != nil {
+		return nil, err
+	}
+	if x == nil {
+		return nil, nil
+	}
+	for _, name := range []string{expr.Sel.Name, upper(expr.Sel.Name)} {
+		if m, ok := x.MethodByName(name); ok {
+			return methodType(m.Type), nil
+		}
+		if x.Kind() == reflect.Pointer {
+			continue
+		}
+		if m, ok := reflect.PointerTo(x).MethodByName(name); ok {
+			return methodType(m.Type), nil
+		}
+	}
+	if x.Kind() == reflect.Pointer {
+		x = x.Elem()
+	}
+	if x.Kind() == reflect.Struct {
+		for _, name := range []string{expr.Sel.Name, upper(expr.Sel.Name)} {
+			if f, ok := x.FieldByName(name); ok {
+				return dynamic(f.Type), nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("no field or method %q on %v", expr.Sel.Name, x)
+}
+
+// methodType returns the type of a method value, which is the method type
+// without the receiver.
+func methodType(t reflect.Type) reflect.Type {
+	in := make([]reflect.Type, 0, t.NumIn()-1)
+	for i := 1; i < t.NumIn(); i++ {
+		in = append(in, t.In(i))
+	}
+	out := make([]reflect.Type, 0, t.NumOut())
+	for i := range t.NumOut() {
+		out = append(out, t.Out(i))
+	}
+	return reflect.FuncOf(in, out, t.IsVariadic())
+}
+
+func (c *checker) checkUnaryExpr(expr *ast.UnaryExpr) (reflect.Type, error) {
+	x, err := c.check(expr.X)
+	if err != nil {
+		return nil, err
+	}
+	switch expr.Op {
+	case token.ADD, token.SUB:
+		if x != nil && !isInt(x) && !isUint(x) && !isFloat(x) {
+			return nil, fmt.Errorf("operator %s not defined for %v", expr.Op, x)
+		}
+		return x, nil
+	case token.XOR:
+		if x != nil && !isInt(x) && !isUint(x) {
+			return nil, fmt.Errorf("operator %s not defined for %v", expr.Op, x)
+		}
+		return x, nil
+	case token.AND:
+		return nil, fmt.Errorf("operator %s not supported", expr.Op)
+	case token.MUL:
+		return uintptrType, nil
+	case token.NOT:
+		if x != nil && x.Kind() != reflect.Bool {
+			return nil, fmt.Errorf("operator %s not defined for %v", expr.Op, x)
+		}
+		return boolType, nil
+	}
+	return nil, fmt.Errorf("unsupported *ast.UnaryExpr: %T", expr.Op)
+}
+
+func (c *checker) checkIndexExpr(expr *ast.IndexExpr) (reflect.Type, error) {
+	x, err := c.check(expr.X)
+	if err != nil {
+		return nil, err
+	}
+	index, err := c.check(expr.Index)
+	if err != nil {
+		return nil, err
+	}
+	if index != nil && !isInt(index) {
+		return nil, fmt.Errorf("invalid index type: %v", index)
+	}
+	if x == nil {
+		return nil, nil
+	}
+	switch x.Kind() {
+	case reflect.Array, reflect.Slice, reflect.String:
+		return dynamic(elemType(x)), nil
+	}
+	return nil, fmt.Errorf("cannot index %v", x)
+}
+
+// elemType returns the type of the elements produced when indexing a value of
+// the provided type.
+func elemType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.String {
+		return reflect.TypeFor[byte]()
+	}
+	return t.Elem()
+}
+
+// convertible returns whether values of one type are implicitly converted to
+// another for operators and function arguments. Unlike
+// [reflect.Type.ConvertibleTo], integers are never convertible to strings,
+// which reflect treats as converting a rune.
+func convertible(from, to reflect.Type) bool {
+	if (isInt(from) || isUint(from)) && to.Kind() == reflect.String {
+		return false
+	}
+	return from.ConvertibleTo(to)
+}
+
+func isInt(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	default:
+		return false
+	}
+}
+
+func isUint(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	default:
+		return false
+	}
+}
+
+func isFloat(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
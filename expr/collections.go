@@ -0,0 +1,334 @@
+package expr
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.chrisrx.dev/x/slices"
+)
+
+// entry is an element of a collection along with its index or map key.
+type entry struct {
+	key, elem reflect.Value
+}
+
+// entries returns the elements of a slice, array or map. Map entries are
+// sorted by key so that iteration order is deterministic.
+func entries(col any) ([]entry, error) {
+	rv := reflect.Indirect(reflect.ValueOf(col))
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array, reflect.String:
+		return slices.Map(slices.N(rv.Len()), func(i int) entry {
+			return entry{key: reflect.ValueOf(i), elem: rv.Index(i)}
+		}), nil
+	case reflect.Map:
+		keys := rv.MapKeys()
+		slices.SortFunc(keys, compareKeys)
+		return slices.Map(keys, func(k reflect.Value) entry {
+			return entry{key: k, elem: rv.MapIndex(k)}
+		}), nil
+	case reflect.Invalid:
+		return nil, nil
+	}
+	return nil, fmt.Errorf("expected slice, array or map, received %T", col)
+}
+
+func compareKeys(a, b reflect.Value) int {
+	switch {
+	case a.CanInt():
+		return cmp.Compare(a.Int(), b.Int())
+	case a.CanUint():
+		return cmp.Compare(a.Uint(), b.Uint())
+	case a.CanFloat():
+		return cmp.Compare(a.Float(), b.Float())
+	case a.Kind() == reflect.String:
+		return cmp.Compare(a.String(), b.String())
+	default:
+		return cmp.Compare(fmt.Sprint(a), fmt.Sprint(b))
+	}
+}
+
+// apply calls a function with an entry of a collection. Functions taking one
+// argument receive the element, functions taking two arguments receive the
+// index or map key and the element.
+func apply(fn any, e entry) (reflect.Value, error) {
+	rv := reflect.ValueOf(fn)
+	if rv.Kind() != reflect.Func {
+		return reflect.Value{}, fmt.Errorf("expected func, received %T", fn)
+	}
+	var args []reflect.Value
+	switch rv.Type().NumIn() {
+	case 1:
+		args = []reflect.Value{e.elem}
+	case 2:
+		args = []reflect.Value{e.key, e.elem}
+	default:
+		return reflect.Value{}, fmt.Errorf("func must take 1 or 2 args, received %v", rv.Type())
+	}
+	for i, arg := range args {
+		in := rv.Type().In(i)
+		switch {
+		case arg.Type().AssignableTo(in):
+		case arg.CanConvert(in):
+			args[i] = arg.Convert(in)
+		default:
+			return reflect.Value{}, fmt.Errorf("cannot convert: %v -> %v", arg.Type(), in)
+		}
+	}
+	return call(rv, args)
+}
+
+// predicate calls a function that must return a bool for an entry of a
+// collection.
+func predicate(fn any, e entry) (bool, error) {
+	v, err := apply(fn, e)
+	if err != nil {
+		return false, err
+	}
+	if v.Kind() != reflect.Bool {
+		return false, fmt.Errorf("func must return bool, received %v", v.Type())
+	}
+	return v.Bool(), nil
+}
+
+// sliceOf makes a slice for the provided values. The element type is the
+// common type of the values, or any if the values have different types.
+func sliceOf(values []reflect.Value) reflect.Value {
+	et := anyType
+	if len(values) > 0 && !slices.ContainsFunc(values, func(v reflect.Value) bool {
+		return v.Type() != values[0].Type()
+	}) {
+		et = values[0].Type()
+	}
+	sv := reflect.MakeSlice(reflect.SliceOf(et), 0, len(values))
+	return reflect.Append(sv, values...)
+}
+
+// equal reports whether two values are equal. Numbers are compared by value
+// regardless of their type, so that untyped literals can be compared with
+// elements of a collection.
+func equal(a, b reflect.Value) bool {
+	if a.Kind() == reflect.Interface && !a.IsNil() {
+		a = a.Elem()
+	}
+	if b.Kind() == reflect.Interface && !b.IsNil() {
+		b = b.Elem()
+	}
+	switch {
+	case !a.IsValid() || !b.IsValid():
+		return a.IsValid() == b.IsValid()
+	case a.CanInt() && b.CanInt():
+		return a.Int() == b.Int()
+	case a.CanUint() && b.CanUint():
+		return a.Uint() == b.Uint()
+	case isNumber(a) && isNumber(b):
+		return a.Convert(float64Type).Float() == b.Convert(float64Type).Float()
+	case a.Kind() == reflect.String && b.Kind() == reflect.String:
+		return a.String() == b.String()
+	case a.Type() == b.Type() && a.Comparable():
+		return a.Equal(b)
+	default:
+		return reflect.DeepEqual(a.Interface(), b.Interface())
+	}
+}
+
+func isNumber(v reflect.Value) bool {
+	return v.CanInt() || v.CanUint() || v.CanFloat()
+}
+
+// inFunc reports whether x is an element of a slice or array, a key of a map
+// or a substring of a string.
+func inFunc(x, col any) (bool, error) {
+	rv := reflect.Indirect(reflect.ValueOf(col))
+	if rv.Kind() == reflect.String {
+		s, ok := x.(string)
+		if !ok {
+			return false, fmt.Errorf("expected string, received %T", x)
+		}
+		return strings.Contains(rv.String(), s), nil
+	}
+	elems, err := entries(col)
+	if err != nil {
+		return false, err
+	}
+	xv := reflect.ValueOf(x)
+	return slices.ContainsFunc(elems, func(e entry) bool {
+		if rv.Kind() == reflect.Map {
+			return equal(xv, e.key)
+		}
+		return equal(xv, e.elem)
+	}), nil
+}
+
+func mapFunc(col, fn any) (any, error) {
+	elems, err := entries(col)
+	if err != nil {
+		return nil, err
+	}
+	values, err := slices.MapErr(elems, func(e entry) (reflect.Value, error) {
+		return apply(fn, e)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sliceOf(values).Interface(), nil
+}
+
+func filterFunc(col, fn any) (any, error) {
+	elems, err := entries(col)
+	if err != nil {
+		return nil, err
+	}
+	var kept []entry
+	for _, e := range elems {
+		ok, err := predicate(fn, e)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			kept = append(kept, e)
+		}
+	}
+	elems = kept
+	rv := reflect.Indirect(reflect.ValueOf(col))
+	if rv.Kind() == reflect.Map {
+		mv := reflect.MakeMapWithSize(rv.Type(), len(elems))
+		for _, e := range elems {
+			mv.SetMapIndex(e.key, e.elem)
+		}
+		return mv.Interface(), nil
+	}
+	return sliceOf(slices.Map(elems, func(e entry) reflect.Value {
+		return e.elem
+	})).Interface(), nil
+}
+
+func countFunc(col, fn any) (int, error) {
+	elems, err := entries(col)
+	if err != nil {
+		return 0, err
+	}
+	var n int
+	for _, e := range elems {
+		ok, err := predicate(fn, e)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func allFunc(col, fn any) (bool, error) {
+	elems, err := entries(col)
+	if err != nil {
+		return false, err
+	}
+	for _, e := range elems {
+		ok, err := predicate(fn, e)
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func anyFunc(col, fn any) (bool, error) {
+	elems, err := entries(col)
+	if err != nil {
+		return false, err
+	}
+	for _, e := range elems {
+		ok, err := predicate(fn, e)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// sumFunc adds the numeric elements of a collection. The result is a float64
+// if any element is a float, a uint64 if all elements are unsigned, and an
+// int64 otherwise.
+func sumFunc(col any) (any, error) {
+	elems, err := entries(col)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		i       int64
+		u       uint64
+		f       float64
+		isFloat bool
+		isUint  = len(elems) > 0
+	)
+	for _, e := range elems {
+		v := e.elem
+		if v.Kind() == reflect.Interface {
+			v = v.Elem()
+		}
+		switch {
+		case v.CanInt():
+			i += v.Int()
+			f += float64(v.Int())
+			isUint = false
+		case v.CanUint():
+			i += int64(v.Uint())
+			u += v.Uint()
+			f += float64(v.Uint())
+		case v.CanFloat():
+			f += v.Float()
+			isFloat = true
+		default:
+			return nil, fmt.Errorf("cannot sum non-numeric value: %v", v.Type())
+		}
+	}
+	switch {
+	case isFloat:
+		return f, nil
+	case isUint:
+		return u, nil
+	default:
+		return i, nil
+	}
+}
+
+func keysFunc(m any) (any, error) {
+	rv := reflect.Indirect(reflect.ValueOf(m))
+	if rv.Kind() != reflect.Map {
+		return nil, fmt.Errorf("expected map, received %T", m)
+	}
+	elems, err := entries(m)
+	if err != nil {
+		return nil, err
+	}
+	sv := reflect.MakeSlice(reflect.SliceOf(rv.Type().Key()), 0, len(elems))
+	return reflect.Append(sv, slices.Map(elems, func(e entry) reflect.Value {
+		return e.key
+	})...).Interface(), nil
+}
+
+func valuesFunc(m any) (any, error) {
+	rv := reflect.Indirect(reflect.ValueOf(m))
+	if rv.Kind() != reflect.Map {
+		return nil, fmt.Errorf("expected map, received %T", m)
+	}
+	elems, err := entries(m)
+	if err != nil {
+		return nil, err
+	}
+	sv := reflect.MakeSlice(reflect.SliceOf(rv.Type().Elem()), 0, len(elems))
+	return reflect.Append(sv, slices.Map(elems, func(e entry) reflect.Value {
+		return e.elem
+	})...).Interface(), nil
+}
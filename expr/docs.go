@@ -0,0 +1,154 @@
+package expr
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"strings"
+
+	"go.chrisrx.dev/x/slices"
+)
+
+// ReferenceURL is the location of the generated reference of the default
+// builtins and packages. It links to the version of this module the program
+// was built with, so the reference matches the builtins that are available,
+// or to the main branch if the version isn't known.
+var ReferenceURL = "https://github.com/ChrisRx/exp/blob/" + cmp.Or(moduleRef(), "main") + "/expr/BUILTINS.md"
+
+// modulePath is the path of the module of this package.
+const modulePath = "go.chrisrx.dev/x"
+
+// moduleRef returns the git ref of the version of this module the program was
+// built with, or an empty string if it isn't known.
+func moduleRef() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, m := range append([]*debug.Module{&info.Main}, info.Deps...) {
+		if m.Path == modulePath && m.Replace == nil {
+			return versionRef(m.Version)
+		}
+	}
+	return ""
+}
+
+// versionRef returns the git ref of a module version, which is the commit of
+// a pseudo-version or the tag of any other version.
+func versionRef(version string) string {
+	version = strings.TrimSuffix(version, "+incompatible")
+	if !strings.HasPrefix(version, "v") {
+		// The main module is "(devel)" when it isn't built from a tag.
+		return ""
+	}
+	parts := strings.Split(version, "-")
+	if n := len(parts); n >= 3 && len(parts[n-1]) == 12 && len(parts[n-2]) >= 14 {
+		return parts[n-1]
+	}
+	return version
+}
+
+// Builtin describes a builtin function or value, or a member of a package.
+type Builtin struct {
+	// Name is the name used in expressions, e.g. "len" or "time.Now".
+	Name string
+
+	// Package is the package of a package member, and empty for builtins.
+	Package string
+
+	// Signature is the name followed by the type of a function, e.g.
+	// "len(any) int", or by the type of a value. A trailing error result is
+	// left out, since it is returned by evaluation rather than used as a
+	// value.
+	Signature string
+
+	// Doc describes the builtin. It is empty for builtins added with
+	// [WithFunc] or [WithPackage].
+	Doc string
+
+	// Capability is the capability that must be allowed to use the builtin
+	// with [Sandbox], if any.
+	Capability Capability
+}
+
+// URL returns the location of the builtin in the reference at
+// [ReferenceURL].
+func (b Builtin) URL() string {
+	return ReferenceURL + "#" + strings.ToLower(strings.ReplaceAll(b.Name, ".", ""))
+}
+
+// Builtins returns the default builtins and package members, sorted by
+// package and name.
+func Builtins() []Builtin {
+	return New().Builtins()
+}
+
+// Builtins returns the builtins and package members available to this Expr,
+// sorted by package and name.
+func (e *Expr) Builtins() []Builtin {
+	var list []Builtin
+	for name, v := range e.builtins {
+		list = append(list, describe(name, "", v))
+	}
+	for pkg, members := range e.packages {
+		for name, v := range members {
+			list = append(list, describe(pkg+"."+name, pkg, v))
+		}
+	}
+	slices.SortFunc(list, func(a, b Builtin) int {
+		return cmp.Or(cmp.Compare(a.Package, b.Package), cmp.Compare(a.Name, b.Name))
+	})
+	return list
+}
+
+func describe(name, pkg string, v reflect.Value) Builtin {
+	return Builtin{
+		Name:       name,
+		Package:    pkg,
+		Signature:  signature(name, v.Type()),
+		Doc:        docs[name],
+		Capability: capabilities[name],
+	}
+}
+
+func signature(name string, t reflect.Type) string {
+	if t.Kind() != reflect.Func {
+		return name + " " + typeString(t)
+	}
+	in := slices.Map(slices.N(t.NumIn()), func(i int) string {
+		if t.IsVariadic() && i == t.NumIn()-1 {
+			return "..." + typeString(t.In(i).Elem())
+		}
+		return typeString(t.In(i))
+	})
+	n := t.NumOut()
+	if n > 0 && t.Out(n-1) == errorType {
+		n--
+	}
+	out := slices.Map(slices.N(n), func(i int) string {
+		return typeString(t.Out(i))
+	})
+	s := fmt.Sprintf("%s(%s)", name, strings.Join(in, ", "))
+	switch len(out) {
+	case 0:
+		return s
+	case 1:
+		return s + " " + out[0]
+	default:
+		return s + " (" + strings.Join(out, ", ") + ")"
+	}
+}
+
+func typeString(t reflect.Type) string {
+	return strings.ReplaceAll(t.String(), "interface {}", "any")
+}
+
+// Builtins returns the builtins and package members referenced by the
+// expression, sorted by package and name.
+func (p *Program) Builtins() []Builtin {
+	return slices.Filter(p.e.Builtins(), func(b Builtin) bool {
+		_, ok := p.builtins[b.Name]
+		return ok
+	})
+}
@@ -0,0 +1,102 @@
+// Code generated by gendocs. DO NOT EDIT.
+
+package expr
+
+// docs are the descriptions of the default builtins and package members.
+var docs = map[string]string{
+	"all":              "Reports whether the function returns true for every element of a slice, array or map.",
+	"any":              "Reports whether the function returns true for any element of a slice, array or map.",
+	"atoi":             "Parses a decimal string as an int.",
+	"base64.Decode":    "Standard base64 decoding of a string.",
+	"base64.Encode":    "Standard base64 encoding of a string or []byte.",
+	"cidr_contains":    "Reports whether an IP address is within a CIDR prefix.",
+	"coalesce":         "Returns the first non-zero value, or the last value if all are zero.",
+	"cond":             "Returns the second argument if the predicate is true, otherwise the third. Both arguments are evaluated.",
+	"contains":         "Same as in, with the arguments reversed.",
+	"count":            "Number of elements of a slice, array or map for which the function returns true.",
+	"date":             "Time for year, month, day, hour, min, sec, nsec and location. Arguments that are left out are zero, except for the month which is January and the location which is UTC.",
+	"duration":         "Parses a duration, e.g. \"1h30m\". Days (\"d\") and weeks (\"w\") are also accepted.",
+	"endswith":         "Reports whether the string ends with the suffix.",
+	"filter":           "Returns the elements of a slice, array or map for which the function returns true. Maps are filtered into a map of the same type.",
+	"find":             "Leftmost match of the regular expression in the string.",
+	"find_all":         "All matches of the regular expression in the string.",
+	"float":            "Converts a number to a float64.",
+	"fmt.Print":        "Same as print.",
+	"fmt.Printf":       "Same as printf.",
+	"fmt.Println":      "Same as println.",
+	"fmt.Sprint":       "Same as sprint.",
+	"fmt.Sprintf":      "Same as sprintf.",
+	"getenv":           "Value of an environment variable, or an empty string if it isn't set.",
+	"getwd":            "Current working directory.",
+	"hmac":             "HMAC-SHA256 hex digest of the data using the key.",
+	"in":               "Reports whether the value is an element of a slice or array, a key of a map or a substring of a string. Numbers are compared by value.",
+	"int":              "Converts a number or a decimal string to an int.",
+	"itoa":             "Formats an int as a decimal string.",
+	"joinpath":         "Joins path elements with the OS specific separator.",
+	"json.Encode":      "Encodes a value as JSON.",
+	"keys":             "Sorted keys of a map.",
+	"len":              "Length of a string, slice, array, map or channel, or 0 for any other value.",
+	"lower":            "Converts a string to lower case.",
+	"map":              "Applies a function to each element of a slice, array or map, returning a slice of the results.",
+	"match":            "Reports whether the string contains a match of the regular expression.",
+	"math.Abs":         "Absolute value.",
+	"math.Acos":        "Arccosine, in radians.",
+	"math.Asin":        "Arcsine, in radians.",
+	"math.Atan":        "Arctangent, in radians.",
+	"math.Ceil":        "Least integer value greater than or equal to the argument.",
+	"math.Cos":         "Cosine of the radian argument.",
+	"math.Exp":         "e**x, the base-e exponential.",
+	"math.Log":         "Natural logarithm.",
+	"math.Max":         "Larger of two numbers.",
+	"math.Min":         "Smaller of two numbers.",
+	"math.Round":       "Nearest integer, rounding half away from zero.",
+	"math.Sin":         "Sine of the radian argument.",
+	"math.Tan":         "Tangent of the radian argument.",
+	"max":              "Larger of two numbers.",
+	"md5":              "MD5 hex digest.",
+	"min":              "Smaller of two numbers.",
+	"net.ParseIP":      "Same as parse_ip.",
+	"none":             "Reports whether the value is zero or nil.",
+	"now":              "Current local time.",
+	"parse_bytes":      "Parses a byte size with an optional SI (\"MB\") or IEC (\"MiB\") unit, e.g. \"512MiB\".",
+	"parse_cidr":       "Parses a CIDR prefix, e.g. \"10.0.0.0/8\".",
+	"parse_ip":         "Parses an IPv4 or IPv6 address, returning nil if it is invalid.",
+	"parse_mac":        "Parses an IEEE 802 MAC address.",
+	"parse_url":        "Parses a URL.",
+	"print":            "Formats the arguments like fmt.Print and writes them to standard output.",
+	"printf":           "Formats the arguments like fmt.Printf and writes them to standard output.",
+	"println":          "Formats the arguments like fmt.Println and writes them to standard output.",
+	"quote":            "Returns a double-quoted Go string literal.",
+	"rand":             "Random int. With one argument n the result is in [0, n), with two arguments min and max it is in [min, max).",
+	"random":           "Random float64 in [0.0, 1.0).",
+	"replace_all":      "Replaces matches of the regular expression with the replacement, which can reference groups with $1.",
+	"semver":           "Parses a semantic version. A leading \"v\" is allowed and the minor and patch versions can be left out. The result has a Compare method that compares it with another version.",
+	"semver_compare":   "Compares two semantic versions, returning -1, 0 or +1.",
+	"sha1":             "SHA-1 hex digest.",
+	"sha256":           "SHA-256 hex digest.",
+	"some":             "Reports whether the value is non-zero and non-nil.",
+	"split":            "Splits a string around each occurrence of the separator.",
+	"split_addr":       "Splits a \"host:port\" address into its host and port.",
+	"sprint":           "Formats the arguments like fmt.Sprint.",
+	"sprintf":          "Formats the arguments like fmt.Sprintf.",
+	"sprintln":         "Formats the arguments like fmt.Sprintln.",
+	"startswith":       "Reports whether the string begins with the prefix.",
+	"string":           "Formats any value as a string.",
+	"sum":              "Sum of the numeric elements of a slice, array or map, as an int64, uint64 or float64.",
+	"tempdir":          "Default directory for temporary files.",
+	"time.Date":        "Same as Go's time.Date.",
+	"time.Duration":    "The time.Duration type, e.g. time.Duration(5) is 5ns.",
+	"time.Hour":        "An hour duration.",
+	"time.Local":       "The system's local time zone.",
+	"time.Millisecond": "A millisecond duration.",
+	"time.Minute":      "A minute duration.",
+	"time.Nanosecond":  "A nanosecond duration.",
+	"time.Now":         "Current local time.",
+	"time.Second":      "A second duration.",
+	"time.Time":        "The time.Time type, e.g. time.Time{} is the zero time.",
+	"time.UTC":         "Coordinated Universal Time.",
+	"trim":             "Removes leading and trailing characters contained in the cutset.",
+	"unquote":          "Interprets a quoted Go string literal.",
+	"upper":            "Converts a string to upper case.",
+	"values":           "Values of a map, sorted by key.",
+}
@@ -0,0 +1,79 @@
+package expr
+
+import (
+	"reflect"
+	"testing"
+
+	"go.chrisrx.dev/x/assert"
+	"go.chrisrx.dev/x/slices"
+)
+
+func TestBuiltins(t *testing.T) {
+	t.Run("documented", func(t *testing.T) {
+		list := Builtins()
+		for _, b := range list {
+			// Something is only registered for tests.
+			if b.Doc == "" && b.Name != "Something" {
+				t.Errorf("builtin %q is missing docs", b.Name)
+			}
+		}
+		for name := range docs {
+			if !slices.ContainsFunc(list, func(b Builtin) bool { return b.Name == name }) {
+				t.Errorf("docs for unknown builtin %q", name)
+			}
+		}
+	})
+
+	t.Run("signatures", func(t *testing.T) {
+		cases := map[string]string{
+			"len":         "len(any) int",
+			"sprintf":     "sprintf(string, ...any) string",
+			"atoi":        "atoi(string) int",
+			"print":       "print(...any) int",
+			"time.Second": "time.Second time.Duration",
+		}
+		list := Builtins()
+		for name, expected := range cases {
+			i := slices.IndexFunc(list, func(b Builtin) bool { return b.Name == name })
+			if i < 0 {
+				t.Fatalf("builtin %q not found", name)
+			}
+			assert.Equal(t, expected, list[i].Signature, name)
+		}
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		e := New(WithFunc("shout", func(s string) string { return s }), WithoutBuiltins("fmt"))
+		list := e.Builtins()
+		i := slices.IndexFunc(list, func(b Builtin) bool { return b.Name == "shout" })
+		if i < 0 {
+			t.Fatal("builtin shout not found")
+		}
+		assert.Equal(t, "shout(string) string", list[i].Signature)
+		assert.Equal(t, "", list[i].Doc)
+		assert.Equal(t, false, slices.ContainsFunc(list, func(b Builtin) bool { return b.Package == "fmt" }))
+	})
+
+	t.Run("reference version", func(t *testing.T) {
+		for version, expected := range map[string]string{
+			"v1.2.3":                               "v1.2.3",
+			"v1.2.3-rc.1":                          "v1.2.3-rc.1",
+			"v2.0.0+incompatible":                  "v2.0.0",
+			"v0.0.0-20250913134956-b84665ba111b":   "b84665ba111b",
+			"v1.2.4-0.20250913134956-b84665ba111b": "b84665ba111b",
+			"(devel)":                              "",
+			"":                                     "",
+		} {
+			assert.Equal(t, expected, versionRef(version), version)
+		}
+	})
+
+	t.Run("referenced by program", func(t *testing.T) {
+		p := MustCompile(`len(split_addr(addr).host) > 0 && time.now().after(time.Time{}) && getenv("X") == ""`, Declare("addr", reflect.TypeFor[string]()))
+		names := slices.Map(p.Builtins(), func(b Builtin) string { return b.Name })
+		assert.Equal(t, []string{"getenv", "len", "split_addr", "time.Now", "time.Time"}, names)
+		assert.Equal(t, Environ, p.Builtins()[0].Capability)
+		assert.Equal(t, ReferenceURL+"#split_addr", p.Builtins()[2].URL())
+		assert.Equal(t, ReferenceURL+"#timenow", p.Builtins()[3].URL())
+	})
+}
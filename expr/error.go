@@ -0,0 +1,106 @@
+package expr
+
+import (
+	"fmt"
+	"go/ast"
+	"go/scanner"
+	"unicode/utf8"
+
+	"go.chrisrx.dev/x/errors"
+	"go.chrisrx.dev/x/strings"
+)
+
+// Error is an error that occurred while compiling or evaluating an
+// expression. It records the position of the sub-expression that caused the
+// error, and the underlying error can be checked with [errors.Is] and
+// [errors.As].
+type Error struct {
+	// Src is the source of the whole expression.
+	Src string
+
+	// Expr is the source of the sub-expression that caused the error. It is
+	// empty for syntax errors.
+	Expr string
+
+	// Offset is the byte offset of the sub-expression in Src, starting at 0.
+	Offset int
+
+	// Line and Column are the position of the sub-expression, starting at 1.
+	// The column is counted in bytes.
+	Line, Column int
+
+	Err error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d:%d: %v", e.Line, e.Column, e.Err)
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Snippet renders the line of the source containing the error, with a caret
+// marking the sub-expression that caused the error:
+//
+//	len(foo) > 1
+//	    ^^^
+func (e *Error) Snippet() string {
+	start := strings.LastIndexByte(e.Src[:e.Offset], '\n') + 1
+	end := len(e.Src)
+	if i := strings.IndexByte(e.Src[e.Offset:], '\n'); i >= 0 {
+		end = e.Offset + i
+	}
+	line := e.Src[start:end]
+
+	// Tabs are preserved so that the caret lines up when the snippet is
+	// printed.
+	indent := strings.Map(func(r rune) rune {
+		if r == '\t' {
+			return r
+		}
+		return ' '
+	}, e.Src[start:e.Offset])
+	n := utf8.RuneCountInString(e.Expr)
+	if i := strings.IndexByte(e.Expr, '\n'); i >= 0 {
+		n = utf8.RuneCountInString(e.Expr[:i])
+	}
+	return line + "\n" + indent + strings.Repeat("^", max(n, 1))
+}
+
+// errorAt returns an [*Error] for the provided node. Errors that already have
+// a position are returned unchanged, so the position is always of the
+// innermost sub-expression that failed.
+func (e *Expr) errorAt(node ast.Node, err error) error {
+	if _, ok := errors.As[*Error](err); ok || e.fset == nil {
+		return err
+	}
+	file := e.fset.File(node.Pos())
+	if file == nil {
+		return err
+	}
+	start, end := file.Offset(node.Pos()), file.Offset(node.End())
+	pos := file.Position(node.Pos())
+	return &Error{
+		Src:    e.src,
+		Expr:   e.src[start:end],
+		Offset: start,
+		Line:   pos.Line,
+		Column: pos.Column,
+		Err:    err,
+	}
+}
+
+// syntaxError converts an error returned by the parser into an [*Error].
+func syntaxError(src string, err error) error {
+	list, ok := errors.As[scanner.ErrorList](err)
+	if !ok || len(list) == 0 {
+		return err
+	}
+	pos := list[0].Pos
+	return &Error{
+		Src:    src,
+		Offset: pos.Offset,
+		Line:   pos.Line,
+		Column: pos.Column,
+		Err:    errors.New(list[0].Msg),
+	}
+}
@@ -0,0 +1,105 @@
+package expr
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"go.chrisrx.dev/x/assert"
+	"go.chrisrx.dev/x/errors"
+)
+
+func TestError(t *testing.T) {
+	cases := []struct {
+		name    string
+		input   string
+		err     string
+		expr    string
+		line    int
+		column  int
+		snippet string
+	}{
+		{
+			name:    "undefined",
+			input:   `len(foo) > 1`,
+			err:     "1:5: undefined: foo",
+			expr:    "foo",
+			line:    1,
+			column:  5,
+			snippet: "len(foo) > 1\n    ^^^",
+		},
+		{
+			name:    "wrong number of args",
+			input:   `1 + split_addr("a", "b").port`,
+			err:     "1:5: func split_addr\\(\\) takes 1 args, received 2",
+			expr:    `split_addr("a", "b")`,
+			line:    1,
+			column:  5,
+			snippet: "1 + split_addr(\"a\", \"b\").port\n    ^^^^^^^^^^^^^^^^^^^^",
+		},
+		{
+			name:    "multiple lines",
+			input:   "1 +\n\tatoi('x')",
+			err:     `2:2: strconv.Atoi: parsing "x": invalid syntax`,
+			expr:    `atoi('x')`,
+			line:    2,
+			column:  2,
+			snippet: "\tatoi('x')\n\t^^^^^^^^^",
+		},
+		{
+			name:    "syntax error",
+			input:   `1 + )`,
+			err:     "1:5: expected operand",
+			line:    1,
+			column:  5,
+			snippet: "1 + )\n    ^",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Eval(tc.input)
+			assert.Error(t, tc.err, err)
+			exprErr, ok := errors.As[*Error](err)
+			if !ok {
+				t.Fatalf("expected *Error, received %T", err)
+			}
+			assert.Equal(t, tc.expr, exprErr.Expr)
+			assert.Equal(t, tc.line, exprErr.Line)
+			assert.Equal(t, tc.column, exprErr.Column)
+			assert.Equal(t, tc.snippet, exprErr.Snippet())
+		})
+	}
+
+	t.Run("builtin errors", func(t *testing.T) {
+		_, err := Eval(`atoi("x")`)
+		assert.Error(t, strconv.ErrSyntax, err)
+
+		_, err = Eval(`unquote("x")`)
+		assert.Error(t, strconv.ErrSyntax, err)
+
+		_, err = Eval(`now() + duration("1 hour")`)
+		assert.Error(t, `1:9: time: unknown unit " hour" in duration "1 hour"`, err)
+
+		_, err = Eval(`int("1.5")`)
+		assert.Error(t, strconv.ErrSyntax, err)
+
+		_, err = Eval(`duration("xd")`)
+		assert.Error(t, `1:1: time: invalid duration "xd"`, err)
+
+		_, err = Eval(`match("[", "a")`)
+		assert.Error(t, "missing closing ]", err)
+
+		_, err = Eval(`semver("1.x")`)
+		assert.Error(t, `invalid semantic version: "1.x"`, err)
+
+		_, err = Eval(`cidr_contains("10.0.0.0/8", "nope")`)
+		assert.Error(t, "ParseAddr", err)
+
+		_, err = Eval(`parse_bytes("10 parsecs")`)
+		assert.Error(t, `invalid byte size unit: "10 parsecs"`, err)
+
+		v, err := Eval(`duration("1h")`)
+		assert.NoError(t, err)
+		assert.Equal(t, time.Hour, v.Interface().(time.Duration))
+	})
+}
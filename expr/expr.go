@@ -4,7 +4,6 @@ import (
 	"errors"
 	"fmt"
 	"go/ast"
-	"go/parser"
 	"go/token"
 	"math/big"
 	"reflect"
@@ -18,35 +17,91 @@ import (
 	"go.chrisrx.dev/x/strings"
 )
 
-// TODO(ChrisRx): pass in variables map
 // TODO(ChrisRx): expose hook function(s)
-// TODO(ChrisRx): make struct with default builtins/funcs that can be modified
-// with constructor options
 
-type Option func(*Expr)
+// Expr evaluates expressions using a configurable set of builtin functions and
+// packages. The zero value is not usable, an Expr must be constructed with
+// [New].
+type Expr struct {
+	env      map[string]reflect.Value
+	types    map[string]reflect.Type
+	builtins map[string]reflect.Value
+	packages map[string]map[string]reflect.Value
+	denied   map[string]Capability
+	limits   limits
+
+	// source of the expression being compiled, used for error positions
+	src  string
+	fset *token.FileSet
+
+	err error
+
+	// evaluation state, see [Expr.start]
+	state *state
+}
 
-func Env(env map[string]reflect.Value) Option {
-	return func(e *Expr) {
-		e.env = env
+// New constructs a new [Expr] using the default builtins and packages, which
+// can be modified with the provided options.
+func New(opts ...Option) *Expr {
+	e := &Expr{
+		builtins: builtins,
+		packages: packages(),
 	}
+	return e.with(opts)
 }
 
-type Expr struct {
-	env map[string]reflect.Value
+// with returns a copy of the Expr with the options applied. Options replace
+// the maps of the Expr rather than modifying them, so the copy never changes
+// the original.
+func (e *Expr) with(opts []Option) *Expr {
+	e2 := *e
+	for _, opt := range opts {
+		opt(&e2)
+	}
+	return &e2
 }
 
-func Eval(s string, opts ...Option) (reflect.Value, error) {
-	s = strings.ReplaceAll(s, `'`, `"`)
-	expr, err := parser.ParseExpr(s)
+// Compile compiles an expression using the builtins and packages of this
+// Expr. See [Compile].
+func (e *Expr) Compile(s string, opts ...Option) (*Program, error) {
+	return compile(s, e.with(opts))
+}
+
+// Eval compiles and evaluates an expression using the builtins and packages
+// of this Expr. See [Eval].
+func (e *Expr) Eval(s string, opts ...Option) (reflect.Value, error) {
+	p, err := e.Compile(s, opts...)
 	if err != nil {
 		return reflect.Value{}, err
 	}
-	e := new(Expr)
-	for _, opt := range opts {
-		opt(e)
-	}
 	// TODO(ChrisRx): check if CanInterface()?
-	return e.eval(expr)
+	return p.Run(p.e.env)
+}
+
+// Eval compiles and evaluates an expression. It is a convenience function for
+// calling [Compile] and [Program.Run] for expressions that are only evaluated
+// once.
+func Eval(s string, opts ...Option) (reflect.Value, error) {
+	return New(opts...).Eval(s)
+}
+
+func (e *Expr) builtin(name string) (reflect.Value, bool) {
+	v, ok := e.builtins[name]
+	return v, ok
+}
+
+// member returns an object from a package. The name is also looked up in Go
+// export case, so that "time.now" finds "time.Now".
+func (e *Expr) member(pkg, name string) (reflect.Value, bool) {
+	members, ok := e.packages[pkg]
+	if !ok {
+		return reflect.Value{}, false
+	}
+	if v, ok := members[name]; ok {
+		return v, true
+	}
+	v, ok := members[upper(name)]
+	return v, ok
 }
 
 func upper(s string) string {
@@ -63,7 +118,24 @@ func upper(s string) string {
 
 var errUndefined = errors.New("undefined")
 
+// ErrDivideByZero is returned when an integer is divided by zero.
+var ErrDivideByZero = errors.New("integer divide by zero")
+
 func (e *Expr) eval(expr ast.Expr) (reflect.Value, error) {
+	if err := e.step(); err != nil {
+		return reflect.Value{}, err
+	}
+	v, err := e.evalExpr(expr)
+	if err == nil {
+		err = e.checkSize(v)
+	}
+	if err != nil {
+		return reflect.Value{}, e.errorAt(expr, err)
+	}
+	return v, nil
+}
+
+func (e *Expr) evalExpr(expr ast.Expr) (reflect.Value, error) {
 	switch expr := expr.(type) {
 	case *ast.ParenExpr:
 		return e.eval(expr.X)
@@ -77,11 +149,11 @@ func (e *Expr) eval(expr ast.Expr) (reflect.Value, error) {
 		if v, ok := e.env[expr.Name]; ok {
 			return v, nil
 		}
-		if v, ok := builtins[expr.Name]; ok {
+		if v, ok := e.builtin(expr.Name); ok {
 			return v, nil
 		}
-		if _, ok := packages()[expr.Name]; ok {
-			return reflect.ValueOf(expr.Name), nil
+		if _, ok := e.packages[expr.Name]; ok {
+			return reflect.Value{}, fmt.Errorf("use of package %s without selector", expr.Name)
 		}
 		return reflect.Value{}, fmt.Errorf("%w: %v", errUndefined, expr.Name)
 	case *ast.BasicLit:
@@ -96,6 +168,8 @@ func (e *Expr) eval(expr ast.Expr) (reflect.Value, error) {
 		return e.evalIndexExpr(expr)
 	case *ast.SliceExpr:
 		return e.evalSliceExpr(expr)
+	case *ast.FuncLit:
+		return e.evalFuncLit(expr)
 	}
 	return reflect.Value{}, fmt.Errorf("unsupported ast.Expr: %T", expr)
 }
@@ -172,7 +246,7 @@ func (e *Expr) evalBinaryExpr(expr *ast.BinaryExpr) (reflect.Value, error) {
 	}
 
 	if lhs.Type() != rhs.Type() {
-		if !lhs.Type().ConvertibleTo(rhs.Type()) {
+		if !convertible(lhs.Type(), rhs.Type()) {
 			return reflect.Value{}, fmt.Errorf("invalid operation: operator %s on incompatible types: %v <> %v", expr.Op, lhs.Type(), rhs.Type())
 		}
 	}
@@ -190,6 +264,13 @@ func (e *Expr) evalBinaryExpr(expr *ast.BinaryExpr) (reflect.Value, error) {
 		lhs = lhs.Convert(rhs.Type())
 	}
 
+	switch expr.Op {
+	case token.QUO, token.REM:
+		if (rhs.CanInt() && rhs.Int() == 0) || (rhs.CanUint() && rhs.Uint() == 0) {
+			return reflect.Value{}, ErrDivideByZero
+		}
+	}
+
 	switch lhs.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		switch expr.Op {
@@ -338,33 +419,31 @@ func (e *Expr) evalCallExpr(expr *ast.CallExpr) (reflect.Value, error) {
 		// return reflect.Value{}, fmt.Errorf("unsupported *ast.CallExpr, must be function: (%v)(%v)", fn.Type(), fn)
 	}
 	var args []reflect.Value
-	if fn.Type().IsVariadic() && len(expr.Args) >= fn.Type().NumIn() {
-		vt := fn.Type().In(max(fn.Type().NumIn()-1, 0)).Elem()
-		for _, arg := range expr.Args {
+	if fn.Type().IsVariadic() && len(expr.Args) >= fn.Type().NumIn()-1 {
+		for i, arg := range expr.Args {
 			v, err := e.eval(arg)
 			if err != nil {
 				return v, err
 			}
+			vt := paramType(fn.Type(), i)
 			switch {
 			case v.Type().AssignableTo(vt):
 				args = append(args, v)
-			case v.CanConvert(vt):
+			case convertible(v.Type(), vt):
 				args = append(args, v.Convert(vt))
 			default:
-				return reflect.Value{}, fmt.Errorf("idk")
+				return reflect.Value{}, fmt.Errorf("cannot convert: %v -> %v", v.Type(), vt)
 			}
 		}
-		results := fn.Call(args)
-		return results[0], nil
+		return call(fn, args)
 	}
 
 	switch {
 	case len(expr.Args) == 0 && fn.Type().NumIn() == 1:
 		if self, ok := e.env["self"]; ok {
 			arg0t := fn.Type().In(0)
-			if self.CanConvert(arg0t) {
-				results := fn.Call([]reflect.Value{self.Convert(arg0t)})
-				return results[0], nil
+			if convertible(self.Type(), arg0t) {
+				return call(fn, []reflect.Value{self.Convert(arg0t)})
 			}
 		}
 	}
@@ -380,12 +459,46 @@ func (e *Expr) evalCallExpr(expr *ast.CallExpr) (reflect.Value, error) {
 		if err != nil {
 			return v, err
 		}
-		if !v.CanConvert(fn.Type().In(i)) {
+		if !convertible(v.Type(), fn.Type().In(i)) {
 			return reflect.Value{}, fmt.Errorf("cannot convert: %v -> %v", v.Type(), fn.Type().In(i))
 		}
 		args = append(args, v.Convert(fn.Type().In(i)))
 	}
+	return call(fn, args)
+}
+
+var errorType = reflect.TypeFor[error]()
+
+// call calls a function and returns its result. If the function also returns
+// an error, it is returned when non-nil. Functions with other results are
+// rejected, see checkResults. Panics are recovered and returned as errors.
+func call(fn reflect.Value, args []reflect.Value) (_ reflect.Value, err error) {
+	if err := checkResults(fn.Type()); err != nil {
+		return reflect.Value{}, fmt.Errorf("func %v %w", fn.Type(), err)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			if lerr, ok := r.(lambdaError); ok {
+				err = lerr.err
+				return
+			}
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
 	results := fn.Call(args)
+	if len(results) == 0 {
+		return reflect.Value{}, fmt.Errorf("func %v (no value) used as value", fn.Type())
+	}
+	if len(results) == 2 {
+		if err, ok := results[1].Interface().(error); ok && err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	// Results returned as an interface are unwrapped, so that the concrete
+	// value is used by the rest of the expression.
+	if results[0].Kind() == reflect.Interface && !results[0].IsNil() {
+		return results[0].Elem(), nil
+	}
 	return results[0], nil
 }
 
@@ -420,43 +533,96 @@ func (e *Expr) evalCompositeLit(expr *ast.CompositeLit) (reflect.Value, error) {
 }
 
 func (e *Expr) evalSelectorExpr(expr *ast.SelectorExpr) (reflect.Value, error) {
+	if pkg, ok := e.pkg(expr.X); ok {
+		if v, ok := e.member(pkg, expr.Sel.Name); ok {
+			return v, nil
+		}
+		return reflect.Value{}, fmt.Errorf("cannot find object in package: %s.%s", pkg, expr.Sel.Name)
+	}
 	x, err := e.eval(expr.X)
 	if err != nil {
 		return reflect.Value{}, err
 	}
+	if x.Kind() == reflect.Interface && !x.IsNil() {
+		x = x.Elem()
+	}
+	if m, ok := methodByName(x, expr.Sel.Name); ok {
+		return m, nil
+	}
+	if x.Kind() == reflect.Pointer && x.IsNil() {
+		return nilSelector(x.Type().Elem(), expr.Sel.Name)
+	}
 	x = reflect.Indirect(x)
-	switch x.Kind() {
-	case reflect.String:
-		if pkg, ok := packages()[x.String()]; ok {
-			if fn, ok := pkg[expr.Sel.Name]; ok {
-				return fn, nil
-			}
-			if fn, ok := pkg[upper(expr.Sel.Name)]; ok {
-				return fn, nil
+	if x.Kind() == reflect.Struct {
+		for _, name := range []string{expr.Sel.Name, upper(expr.Sel.Name)} {
+			if field := x.FieldByName(name); field.IsValid() {
+				return field, nil
 			}
-			return reflect.Value{}, fmt.Errorf("cannot find object in package: %s.%s", x, expr.Sel.Name)
 		}
-		return reflect.Value{}, fmt.Errorf("cannot find package: %s", x)
-	case reflect.Struct:
-		method := x.MethodByName(expr.Sel.Name)
-		if method.IsValid() {
-			return method, nil
+	}
+	return reflect.Value{}, fmt.Errorf("no field or method %q on %v", expr.Sel.Name, x.Type())
+}
+
+// pkg returns the name of the package an identifier refers to, if the
+// identifier isn't shadowed by a variable or builtin.
+func (e *Expr) pkg(expr ast.Expr) (string, bool) {
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+	if _, ok := e.env[ident.Name]; ok {
+		return "", false
+	}
+	if _, ok := e.builtin(ident.Name); ok {
+		return "", false
+	}
+	_, ok = e.packages[ident.Name]
+	return ident.Name, ok
+}
+
+// methodByName returns a method value for the named method of a value. The
+// name is also looked up in Go export case, so that "is_zero" finds
+// "IsZero". Methods with pointer receivers can be called on values that
+// aren't pointers, in which case the method is called on the value if it is
+// addressable or on a copy of it otherwise. Values passed with [Env] aren't
+// addressable, so a method that changes its receiver only changes the copy,
+// and variables must be pointers for the changes to be kept.
+func methodByName(v reflect.Value, name string) (reflect.Value, bool) {
+	if !v.IsValid() {
+		return reflect.Value{}, false
+	}
+	for _, name := range []string{name, upper(name)} {
+		if m := v.MethodByName(name); m.IsValid() {
+			return m, true
 		}
-		method = x.MethodByName(upper(expr.Sel.Name))
-		if method.IsValid() {
-			return method, nil
+		if v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+			continue
 		}
-		field := x.FieldByName(expr.Sel.Name)
-		if field.IsValid() {
-			return field, nil
+		if _, ok := reflect.PointerTo(v.Type()).MethodByName(name); ok {
+			if v.CanAddr() {
+				return v.Addr().MethodByName(name), true
+			}
+			p := reflect.New(v.Type())
+			p.Elem().Set(v)
+			return p.MethodByName(name), true
 		}
-		field = x.FieldByName(upper(expr.Sel.Name))
-		if field.IsValid() {
-			return field, nil
+	}
+	return reflect.Value{}, false
+}
+
+// nilSelector selects a field of a nil struct pointer. Fields of a nil pointer
+// evaluate to the zero value of the field, so that optional nested structs can
+// be accessed without checking each pointer, e.g. "self.TLS.CertFile".
+func nilSelector(t reflect.Type, name string) (reflect.Value, error) {
+	if t.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("invalid memory address or nil pointer dereference: %v.%s", t, name)
+	}
+	for _, name := range []string{name, upper(name)} {
+		if f, ok := t.FieldByName(name); ok {
+			return reflect.Zero(f.Type), nil
 		}
-		return reflect.Value{}, fmt.Errorf("no field or method %q on %v", expr.Sel.Name, x.Type())
 	}
-	return reflect.Value{}, fmt.Errorf("unsupported *ast.SelectorExpr: (%v)(%v)", x.Type(), x)
+	return reflect.Value{}, fmt.Errorf("invalid memory address or nil pointer dereference: %v.%s", t, name)
 }
 
 func (e *Expr) evalUnaryExpr(expr *ast.UnaryExpr) (reflect.Value, error) {
@@ -464,26 +630,49 @@ func (e *Expr) evalUnaryExpr(expr *ast.UnaryExpr) (reflect.Value, error) {
 	if err != nil {
 		return reflect.Value{}, err
 	}
+	if v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
 	switch expr.Op {
-	case token.ADD:
-		return reflect.ValueOf(+v.Int()), nil
-	case token.SUB:
-		return reflect.ValueOf(-v.Int()), nil
+	case token.ADD, token.SUB, token.XOR:
+		// The result has the type of the operand, e.g. -time.Minute is a
+		// time.Duration, and negating an unsigned integer wraps around like
+		// in Go.
+		result := reflect.New(v.Type()).Elem()
+		switch {
+		case v.CanInt():
+			switch expr.Op {
+			case token.ADD:
+				result.SetInt(v.Int())
+			case token.SUB:
+				result.SetInt(-v.Int())
+			case token.XOR:
+				result.SetInt(^v.Int())
+			}
+			return result, nil
+		case v.CanUint():
+			switch expr.Op {
+			case token.ADD:
+				result.SetUint(v.Uint())
+			case token.SUB:
+				result.SetUint(-v.Uint())
+			case token.XOR:
+				result.SetUint(^v.Uint())
+			}
+			return result, nil
+		case v.CanFloat() && expr.Op != token.XOR:
+			if expr.Op == token.SUB {
+				result.SetFloat(-v.Float())
+			} else {
+				result.SetFloat(v.Float())
+			}
+			return result, nil
+		}
+		return reflect.Value{}, fmt.Errorf("operator %s not defined for %v", expr.Op, v.Type())
 	case token.MUL:
 		return reflect.ValueOf(v.Pointer()), nil
-	case token.AND:
-		return reflect.ValueOf(^v.Int()), nil
 	case token.NOT:
-		// if v.Kind() == reflect.Interface {
-		// 	b, ok := v.Interface().(bool)
-		// 	if !ok {
-		// 		return reflect.Value{}, fmt.Errorf("received invalid type %T for unary operator %T", v.Interface(), expr.Op)
-		// 	}
-		// 	v = reflect.ValueOf(b)
-		// }
 		return reflect.ValueOf(!v.Bool()), nil
-	case token.XOR:
-		return reflect.ValueOf(^v.Int()), nil
 	}
 	return reflect.Value{}, fmt.Errorf("unsupported *ast.UnaryExpr: %T", expr.Op)
 }
@@ -502,7 +691,7 @@ func (e *Expr) evalIndexExpr(expr *ast.IndexExpr) (reflect.Value, error) {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		index := int(index.Int())
 		if v.Len() <= index {
-			return reflect.New(v.Type()).Elem(), nil
+			return reflect.New(elemType(v.Type())).Elem(), nil
 		}
 		elem := v.Index(index)
 		if elem.IsValid() {
@@ -2,7 +2,9 @@ package expr
 
 import (
 	"fmt"
+	"net"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -14,6 +16,38 @@ type Something struct {
 	N int
 }
 
+type hostname string
+
+func (h hostname) String() string { return "host:" + string(h) }
+
+type server struct {
+	Host hostname
+	port int
+}
+
+func (s *server) Addr() *addr { return &addr{host: string(s.Host), port: s.port} }
+
+func (s *server) SetPort(port int) int {
+	s.port = port
+	return s.port
+}
+
+type addr struct {
+	host string
+	port int
+}
+
+func (a addr) Port() int { return a.port }
+
+func (a *addr) Lookup() (string, error) {
+	if a.host == "" {
+		return "", fmt.Errorf("lookup %q: no such host", a.host)
+	}
+	return a.host, nil
+}
+
+func (a addr) Split() (string, int) { return a.host, a.port }
+
 func init() {
 	enableTesting()
 
@@ -115,6 +149,34 @@ func TestEval(t *testing.T) {
 		})
 	})
 
+	t.Run("unary operators", func(t *testing.T) {
+		runAll(t, []testCase{
+			{name: "negate int", input: `-5`, expected: int64(-5)},
+			{name: "negate float", input: `-1.5`, expected: -1.5},
+			{name: "plus float", input: `+1.5`, expected: 1.5},
+			{name: "bitwise not", input: `^5`, expected: int64(^5)},
+			{name: "negate duration", input: `-time.Minute`, expected: -time.Minute},
+			{
+				name:     "negate float variable",
+				input:    `-self`,
+				env:      map[string]reflect.Value{"self": reflect.ValueOf(2.5)},
+				expected: -2.5,
+			},
+			{
+				name:     "negate uint",
+				input:    `-self`,
+				env:      map[string]reflect.Value{"self": reflect.ValueOf(uint8(1))},
+				expected: uint8(255),
+			},
+			{
+				name:     "bitwise not uint",
+				input:    `^self`,
+				env:      map[string]reflect.Value{"self": reflect.ValueOf(uint16(0))},
+				expected: uint16(0xffff),
+			},
+		})
+	})
+
 	t.Run("time", func(t *testing.T) {
 		runAll(t, []testCase{
 			{
@@ -168,6 +230,161 @@ func TestEval(t *testing.T) {
 		})
 	})
 
+	t.Run("func literals", func(t *testing.T) {
+		runAll(t, []testCase{
+			{name: "typed", input: `func(x int) bool { return x > 0 }(5)`, expected: true},
+			{name: "untyped", input: `func(x) { return x * 2 }(5)`, expected: int64(10)},
+			{name: "conversion of result", input: `func(x int) int { return x * 2 }(5)`, expected: 10},
+			{name: "multiple params", input: `func(a, b string) string { return a + b }("a", "b")`, expected: "ab"},
+			{
+				name:     "closure",
+				input:    `func(p) { return p < max }(80)`,
+				env:      map[string]reflect.Value{"max": reflect.ValueOf(100)},
+				expected: true,
+			},
+		})
+	})
+
+	t.Run("collections", func(t *testing.T) {
+		env := map[string]reflect.Value{
+			"ports":  reflect.ValueOf([]int{80, 443, 8080}),
+			"hosts":  reflect.ValueOf(map[string]int{"a": 1, "b": 2, "c": 3}),
+			"floats": reflect.ValueOf([]float64{1.5, 2.5}),
+		}
+		runAll(t, []testCase{
+			{name: "all", input: `all(ports, func(p) { return p < 65536 })`, env: env, expected: true},
+			{name: "all false", input: `all(ports, func(p int) bool { return p < 1024 })`, env: env, expected: false},
+			{name: "any", input: `any(ports, func(p) { return p == 443 })`, env: env, expected: true},
+			{name: "count", input: `count(ports, func(p) { return p > 100 })`, env: env, expected: 2},
+			{name: "filter", input: `filter(ports, func(p) { return p > 100 })`, env: env, expected: []int{443, 8080}},
+			{name: "map", input: `map(ports, func(p) { return p + 1 })`, env: env, expected: []int64{81, 444, 8081}},
+			{name: "map typed", input: `map(ports, func(p int) string { return sprint(p) })`, env: env, expected: []string{"80", "443", "8080"}},
+			{name: "map with index", input: `map(ports, func(i, p) { return i })`, env: env, expected: []int{0, 1, 2}},
+			{name: "sum", input: `sum(ports)`, env: env, expected: int64(8603)},
+			{name: "sum floats", input: `sum(floats)`, env: env, expected: 4.0},
+			{name: "sum of map", input: `sum(map(ports, func(p) { return p * 2 }))`, env: env, expected: int64(17206)},
+			{name: "keys", input: `keys(hosts)`, env: env, expected: []string{"a", "b", "c"}},
+			{name: "values", input: `values(hosts)`, env: env, expected: []int{1, 2, 3}},
+			{name: "filter map", input: `filter(hosts, func(k, v) { return k != "b" })`, env: env, expected: map[string]int{"a": 1, "c": 3}},
+			{name: "len of filter", input: `len(filter(ports, func(p) { return p > 100 })) == 2`, env: env, expected: true},
+		})
+
+		t.Run("stop at first error", func(t *testing.T) {
+			for _, name := range []string{"all", "any", "count", "filter", "map"} {
+				var calls int
+				_, err := Eval(name+`(ports, func(p) { return check(p) })`, Env(env), WithFunc("check", func(p int) (bool, error) {
+					calls++
+					return false, fmt.Errorf("port %d", p)
+				}))
+				assert.Error(t, "port 80", err, name)
+				assert.Equal(t, 1, calls, name)
+			}
+		})
+	})
+
+	t.Run("conditionals", func(t *testing.T) {
+		env := map[string]reflect.Value{
+			"ports": reflect.ValueOf([]int{80, 443}),
+			"hosts": reflect.ValueOf(map[string]int{"a": 1}),
+			"name":  reflect.ValueOf(""),
+		}
+		runAll(t, []testCase{
+			{name: "cond true", input: `cond(len(ports) > 1, "many", "one")`, env: env, expected: "many"},
+			{name: "cond false", input: `cond(len(ports) > 5, "many", "few")`, env: env, expected: "few"},
+			{name: "coalesce", input: `coalesce(name, "default")`, env: env, expected: "default"},
+			{name: "coalesce first", input: `coalesce("value", name)`, env: env, expected: "value"},
+			{name: "coalesce all zero", input: `coalesce(name, "")`, env: env, expected: ""},
+			{name: "in slice", input: `in(443, ports)`, env: env, expected: true},
+			{name: "not in slice", input: `in(8080, ports)`, env: env, expected: false},
+			{name: "in map", input: `in("a", hosts)`, env: env, expected: true},
+			{name: "in string", input: `in("ell", "hello")`, expected: true},
+			{name: "contains", input: `contains(ports, 80)`, env: env, expected: true},
+			{name: "contains literal", input: `contains(split("a,b", ","), "c")`, expected: false},
+		})
+	})
+
+	t.Run("nil-safe selectors", func(t *testing.T) {
+		type TLS struct {
+			CertFile string
+			Inner    *TLS
+		}
+		type Config struct {
+			TLS *TLS
+		}
+		env := map[string]reflect.Value{
+			"self": reflect.ValueOf(Config{}),
+			"set":  reflect.ValueOf(Config{TLS: &TLS{CertFile: "cert.pem"}}),
+		}
+		runAll(t, []testCase{
+			{name: "nil pointer field", input: `self.TLS.CertFile`, env: env, expected: ""},
+			{name: "nested nil pointer field", input: `self.TLS.Inner.Inner.CertFile`, env: env, expected: ""},
+			{name: "snake case", input: `self.TLS.cert_file == ""`, env: env, expected: true},
+			{name: "non-nil pointer field", input: `set.TLS.CertFile`, env: env, expected: "cert.pem"},
+			{name: "coalesce", input: `coalesce(self.TLS.CertFile, set.TLS.CertFile)`, env: env, expected: "cert.pem"},
+		})
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		runAll(t, []testCase{
+			{name: "match", input: `match("^[a-z]+-[0-9]+$", "web-01")`, expected: true},
+			{name: "no match", input: `match("^[a-z]+$", "web-01")`, expected: false},
+			{name: "find", input: `find("[0-9]+", "web-01")`, expected: "01"},
+			{name: "find all", input: `find_all("[0-9]+", "a1b22c333")`, expected: []string{"1", "22", "333"}},
+			{name: "replace all", input: `replace_all("[0-9]", "a1b2", "#")`, expected: "a#b#"},
+			{name: "semver", input: `semver("v1.2.3-rc.1+build").major`, expected: 1},
+			{name: "semver prerelease", input: `semver("1.2.3-rc.1").prerelease`, expected: "rc.1"},
+			{name: "semver compare method", input: `semver("1.10.0").compare("1.9.0") > 0`, expected: true},
+			{name: "semver compare", input: `semver_compare("1.0.0-alpha", "1.0.0")`, expected: -1},
+			{name: "semver compare prerelease", input: `semver_compare("1.0.0-alpha.10", "1.0.0-alpha.2")`, expected: 1},
+			{name: "semver compare short", input: `semver_compare("v2", "2.0.0")`, expected: 0},
+			{name: "cidr contains", input: `cidr_contains("10.0.0.0/8", "10.1.2.3")`, expected: true},
+			{name: "cidr not contains", input: `cidr_contains("10.0.0.0/8", "192.168.0.1")`, expected: false},
+			{name: "cidr contains ipv6", input: `cidr_contains("fd00::/8", "fd12::1")`, expected: true},
+			{name: "parse cidr", input: `parse_cidr("10.0.0.0/8").bits()`, expected: 8},
+			{name: "url scheme", input: `parse_url("https://example.com:8443/path").scheme`, expected: "https"},
+			{name: "url host", input: `parse_url("https://example.com:8443/path").host`, expected: "example.com:8443"},
+			{name: "duration days", input: `duration("1d12h")`, expected: 36 * time.Hour},
+			{name: "duration weeks", input: `duration("1w") == duration("7d")`, expected: true},
+			{name: "duration fractional days", input: `duration("1.5d")`, expected: 36 * time.Hour},
+			{name: "bytes iec", input: `parse_bytes("512MiB")`, expected: 512 << 20},
+			{name: "bytes si", input: `parse_bytes("1.5GB")`, expected: 1_500_000_000},
+			{name: "bytes no unit", input: `parse_bytes("1024")`, expected: 1024},
+			{name: "bytes lowercase", input: `parse_bytes("4kib") == 4096`, expected: true},
+		})
+	})
+
+	t.Run("methods", func(t *testing.T) {
+		env := map[string]reflect.Value{
+			"self":  reflect.ValueOf(&server{Host: "example.com", port: 8080}),
+			"value": reflect.ValueOf(server{Host: "example.com", port: 443}),
+			"ip":    reflect.ValueOf(net.ParseIP("10.0.0.1")),
+			"d":     reflect.ValueOf(90 * time.Second),
+		}
+		runAll(t, []testCase{
+			{name: "value receiver on named string", input: `self.Host.String()`, env: env, expected: "host:example.com"},
+			{name: "pointer receiver", input: `self.Addr().Port()`, env: env, expected: 8080},
+			{name: "pointer receiver on value", input: `value.addr().port()`, env: env, expected: 443},
+			{name: "snake case", input: `self.set_port(9090)`, env: env, expected: 9090},
+			{name: "pointer receiver returning error", input: `self.Addr().Lookup()`, env: env, expected: "example.com"},
+			{name: "slice type", input: `ip.String()`, env: env, expected: "10.0.0.1"},
+			{name: "integer type", input: `d.minutes()`, env: env, expected: 1.5},
+			{name: "builtin result", input: `parse_url("https://example.com:8443").hostname()`, expected: "example.com"},
+			{name: "builtin result with args", input: `parse_url("https://example.com:8443").port() == "8443"`, expected: true},
+		})
+
+		_, err := Eval(`self.Addr().Lookup()`, Env(map[string]reflect.Value{
+			"self": reflect.ValueOf(&server{}),
+		}))
+		assert.Error(t, `1:1: lookup "": no such host`, err)
+
+		// Results other than a trailing error would be dropped, so these
+		// methods can't be called.
+		_, err = Eval(`self.Addr().Split()`, Env(env))
+		assert.Error(t, `func Split\(\) returns 2 values, only a value or a value and an error can be used`, err)
+		_, err = Eval(`strings.Cut("a=b", "=")`, WithPackage("strings", map[string]any{"Cut": strings.Cut}))
+		assert.Error(t, `func Cut\(\) returns 3 values`, err)
+	})
+
 	t.Run("misc", func(t *testing.T) {
 		runAll(t, []testCase{
 			{
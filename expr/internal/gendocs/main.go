@@ -0,0 +1,177 @@
+// Command gendocs generates the docs of the default expr builtins and
+// packages from the comments of their registrations in builtins.go. It writes
+// the docs returned by expr.Builtins to docs_gen.go, and the reference of the
+// builtins as markdown.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.chrisrx.dev/x/expr"
+)
+
+var (
+	src    = flag.String("src", "builtins.go", "file with the builtin registrations")
+	gen    = flag.String("gen", "docs_gen.go", "output file for the docs")
+	output = flag.String("o", "BUILTINS.md", "output file for the reference")
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("gendocs: ")
+	flag.Parse()
+
+	docs, err := parseDocs(*src)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*gen, generate(docs), 0o644); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*output, reference(docs), 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// parseDocs returns the doc comments of the builtins and package members
+// registered in a file, keyed by the name used in expressions. A doc comment
+// is a comment that ends on the line before the registration, so comments
+// separated by a blank line, like the ones naming a group of builtins, are
+// left out.
+func parseDocs(filename string) (map[string]string, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filename, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	comments := make(map[int]*ast.CommentGroup)
+	for _, c := range f.Comments {
+		comments[fset.Position(c.End()).Line] = c
+	}
+	docs := make(map[string]string)
+	var collect func(prefix string, lit *ast.CompositeLit)
+	collect = func(prefix string, lit *ast.CompositeLit) {
+		for _, elt := range lit.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				continue
+			}
+			key, ok := kv.Key.(*ast.BasicLit)
+			if !ok || key.Kind != token.STRING {
+				continue
+			}
+			name, err := strconv.Unquote(key.Value)
+			if err != nil {
+				continue
+			}
+			// Packages are maps of their members.
+			if members, ok := kv.Value.(*ast.CompositeLit); ok && prefix == "" && members.Type == nil {
+				collect(name+".", members)
+				continue
+			}
+			if c, ok := comments[fset.Position(kv.Pos()).Line-1]; ok {
+				docs[prefix+name] = strings.Join(strings.Fields(c.Text()), " ")
+			}
+		}
+	}
+	for _, decl := range f.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			vs := spec.(*ast.ValueSpec)
+			if len(vs.Names) != 1 || len(vs.Values) != 1 {
+				continue
+			}
+			switch vs.Names[0].Name {
+			case "builtins", "packages":
+			default:
+				continue
+			}
+			// The packages are returned by a function, so the first map
+			// literal is the one with the registrations.
+			ast.Inspect(vs.Values[0], func(n ast.Node) bool {
+				lit, ok := n.(*ast.CompositeLit)
+				if !ok {
+					return true
+				}
+				collect("", lit)
+				return false
+			})
+		}
+	}
+	return docs, nil
+}
+
+// generate returns the source of the docs map.
+func generate(docs map[string]string) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintln(&buf, "// Code generated by gendocs. DO NOT EDIT.")
+	fmt.Fprintln(&buf)
+	fmt.Fprintln(&buf, "package expr")
+	fmt.Fprintln(&buf)
+	fmt.Fprintln(&buf, "// docs are the descriptions of the default builtins and package members.")
+	fmt.Fprintln(&buf, "var docs = map[string]string{")
+	for _, name := range slices.Sorted(maps.Keys(docs)) {
+		fmt.Fprintf(&buf, "\t%q: %q,\n", name, docs[name])
+	}
+	fmt.Fprintln(&buf, "}")
+	data, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	return data
+}
+
+// reference returns the reference of the default builtins as markdown. The
+// docs are taken from the registrations rather than [expr.Builtins], which
+// returns the docs from before they were generated again.
+func reference(docs map[string]string) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintln(&buf, "<!-- Code generated by gendocs. DO NOT EDIT. -->")
+	fmt.Fprintln(&buf)
+	fmt.Fprintln(&buf, "# Builtins")
+	fmt.Fprintln(&buf)
+	fmt.Fprintln(&buf, "Reference of the builtins and packages available to expressions by default.")
+
+	pkg := ""
+	for i, b := range expr.Builtins() {
+		if i == 0 || b.Package != pkg {
+			pkg = b.Package
+			fmt.Fprintln(&buf)
+			if pkg == "" {
+				fmt.Fprintln(&buf, "## Functions and values")
+			} else {
+				fmt.Fprintf(&buf, "## Package `%s`\n", pkg)
+			}
+		}
+		fmt.Fprintln(&buf)
+		fmt.Fprintf(&buf, "### %s\n", b.Name)
+		fmt.Fprintln(&buf)
+		fmt.Fprintln(&buf, "```go")
+		fmt.Fprintln(&buf, b.Signature)
+		fmt.Fprintln(&buf, "```")
+		if doc := docs[b.Name]; doc != "" {
+			fmt.Fprintln(&buf)
+			fmt.Fprintln(&buf, doc)
+		}
+		if b.Capability != "" {
+			fmt.Fprintln(&buf)
+			fmt.Fprintf(&buf, "Requires the `%s` capability when sandboxed.\n", b.Capability)
+		}
+	}
+	return buf.Bytes()
+}
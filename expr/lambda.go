@@ -0,0 +1,269 @@
+package expr
+
+import (
+	"fmt"
+	"go/ast"
+	"go/scanner"
+	"go/token"
+	"reflect"
+
+	"go.chrisrx.dev/x/maps"
+)
+
+// Function literals are supported when the body is a single return
+// statement:
+//
+//	func(x int) bool { return x > 0 }
+//
+// Parameter and result types can be left out, in which case they are any:
+//
+//	func(x) { return x > 0 }
+//
+// Since Go requires unnamed parameters to be types, a parameter without a name
+// is always treated as the name of an untyped parameter.
+
+var basicTypes = map[string]reflect.Type{
+	"any":     reflect.TypeFor[any](),
+	"bool":    reflect.TypeFor[bool](),
+	"byte":    reflect.TypeFor[byte](),
+	"error":   reflect.TypeFor[error](),
+	"float32": reflect.TypeFor[float32](),
+	"float64": reflect.TypeFor[float64](),
+	"int":     reflect.TypeFor[int](),
+	"int8":    reflect.TypeFor[int8](),
+	"int16":   reflect.TypeFor[int16](),
+	"int32":   reflect.TypeFor[int32](),
+	"int64":   reflect.TypeFor[int64](),
+	"rune":    reflect.TypeFor[rune](),
+	"string":  reflect.TypeFor[string](),
+	"uint":    reflect.TypeFor[uint](),
+	"uint8":   reflect.TypeFor[uint8](),
+	"uint16":  reflect.TypeFor[uint16](),
+	"uint32":  reflect.TypeFor[uint32](),
+	"uint64":  reflect.TypeFor[uint64](),
+}
+
+var anyType = reflect.TypeFor[any]()
+
+// resolveType returns the type for a type expression. Besides the basic Go
+// types, named types are resolved from the type of builtin and package values,
+// e.g. "time.Duration".
+func (e *Expr) resolveType(expr ast.Expr) (reflect.Type, error) {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		if t, ok := basicTypes[expr.Name]; ok {
+			return t, nil
+		}
+		if v, ok := e.builtin(expr.Name); ok && v.Kind() != reflect.Func {
+			return v.Type(), nil
+		}
+	case *ast.SelectorExpr:
+		if pkg, ok := expr.X.(*ast.Ident); ok {
+			if v, ok := e.member(pkg.Name, expr.Sel.Name); ok && v.Kind() != reflect.Func {
+				return v.Type(), nil
+			}
+		}
+	case *ast.ParenExpr:
+		return e.resolveType(expr.X)
+	case *ast.StarExpr:
+		t, err := e.resolveType(expr.X)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.PointerTo(t), nil
+	case *ast.ArrayType:
+		t, err := e.resolveType(expr.Elt)
+		if err != nil {
+			return nil, err
+		}
+		if expr.Len != nil {
+			return nil, fmt.Errorf("unsupported array type, use a slice instead")
+		}
+		return reflect.SliceOf(t), nil
+	case *ast.MapType:
+		k, err := e.resolveType(expr.Key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := e.resolveType(expr.Value)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.MapOf(k, v), nil
+	case *ast.InterfaceType:
+		if len(expr.Methods.List) == 0 {
+			return anyType, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported type: %T", expr)
+}
+
+// lambda is the signature of a function literal.
+type lambda struct {
+	names []string
+	typ   reflect.Type
+	body  ast.Expr
+}
+
+func (e *Expr) lambda(expr *ast.FuncLit) (*lambda, error) {
+	l := &lambda{}
+	var in, out []reflect.Type
+	for _, field := range expr.Type.Params.List {
+		if len(field.Names) == 0 {
+			name, ok := field.Type.(*ast.Ident)
+			if !ok {
+				return nil, fmt.Errorf("func literal parameters must be named")
+			}
+			l.names = append(l.names, name.Name)
+			in = append(in, anyType)
+			continue
+		}
+		t, err := e.resolveType(field.Type)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range field.Names {
+			l.names = append(l.names, name.Name)
+			in = append(in, t)
+		}
+	}
+	switch {
+	case expr.Type.Results == nil:
+		out = []reflect.Type{anyType}
+	case expr.Type.Results.NumFields() == 1:
+		t, err := e.resolveType(expr.Type.Results.List[0].Type)
+		if err != nil {
+			return nil, err
+		}
+		out = []reflect.Type{t}
+	default:
+		return nil, fmt.Errorf("func literal must have a single result")
+	}
+	l.typ = reflect.FuncOf(in, out, false)
+
+	if len(expr.Body.List) != 1 {
+		return nil, fmt.Errorf("func literal body must be a single return statement")
+	}
+	ret, ok := expr.Body.List[0].(*ast.ReturnStmt)
+	if !ok || len(ret.Results) != 1 {
+		return nil, fmt.Errorf("func literal body must be a single return statement")
+	}
+	l.body = ret.Results[0]
+	return l, nil
+}
+
+// lambdaError is used to return an error from a function literal, since a
+// function created with [reflect.MakeFunc] can only return its declared
+// results. It is recovered by [call].
+type lambdaError struct {
+	err error
+}
+
+func (e *Expr) evalFuncLit(expr *ast.FuncLit) (reflect.Value, error) {
+	l, err := e.lambda(expr)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return reflect.MakeFunc(l.typ, func(args []reflect.Value) []reflect.Value {
+		scope := *e
+		scope.env = maps.Clone(e.env)
+		if scope.env == nil {
+			scope.env = make(map[string]reflect.Value, len(args))
+		}
+		for i, arg := range args {
+			if arg.Kind() == reflect.Interface && !arg.IsNil() {
+				arg = arg.Elem()
+			}
+			scope.env[l.names[i]] = arg
+		}
+		v, err := scope.eval(l.body)
+		if err != nil {
+			panic(lambdaError{err})
+		}
+		out := l.typ.Out(0)
+		switch {
+		case !v.IsValid():
+			v = reflect.Zero(out)
+		case !v.Type().AssignableTo(out) && v.CanConvert(out):
+			v = v.Convert(out)
+		case !v.Type().AssignableTo(out):
+			panic(lambdaError{fmt.Errorf("cannot use %v as %v value in return statement", v.Type(), out)})
+		}
+		return []reflect.Value{v}
+	}), nil
+}
+
+func (c *checker) checkFuncLit(expr *ast.FuncLit) (reflect.Type, error) {
+	l, err := c.e.lambda(expr)
+	if err != nil {
+		return nil, err
+	}
+	scope := c.scope
+	c.scope = maps.Clone(scope)
+	if c.scope == nil {
+		c.scope = make(map[string]reflect.Type, len(l.names))
+	}
+	for i, name := range l.names {
+		c.scope[name] = l.typ.In(i)
+	}
+	defer func() { c.scope = scope }()
+
+	t, err := c.check(l.body)
+	if err != nil {
+		return nil, err
+	}
+	if out := l.typ.Out(0); t != nil && !t.AssignableTo(out) && !t.ConvertibleTo(out) {
+		return nil, fmt.Errorf("cannot use %v as %v value in return statement", t, out)
+	}
+	return l.typ, nil
+}
+
+// mapPlaceholder replaces the map keyword when used as a function call, so that
+// the source can be parsed. It must be the same length as "map" so that
+// positions don't change.
+const mapPlaceholder = "_m_"
+
+// rewriteKeywords replaces keywords that are used as builtin function names,
+// returning the offsets of the replaced keywords. The Go parser would
+// otherwise reject "map(...)" since map is a keyword.
+func rewriteKeywords(src string) (string, []int) {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+	var s scanner.Scanner
+	s.Init(file, []byte(src), nil, 0)
+
+	var offsets []int
+	data := []byte(src)
+	prev, prevPos := token.ILLEGAL, token.NoPos
+	for {
+		pos, tok, _ := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if prev == token.MAP && tok == token.LPAREN {
+			offset := file.Offset(prevPos)
+			copy(data[offset:], mapPlaceholder)
+			offsets = append(offsets, offset)
+		}
+		prev, prevPos = tok, pos
+	}
+	return string(data), offsets
+}
+
+// restoreKeywords restores the names of identifiers replaced by
+// [rewriteKeywords].
+func restoreKeywords(file *token.File, expr ast.Expr, offsets []int) {
+	if len(offsets) == 0 {
+		return
+	}
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok && ident.Name == mapPlaceholder {
+			for _, offset := range offsets {
+				if file.Offset(ident.Pos()) == offset {
+					ident.Name = "map"
+				}
+			}
+		}
+		return true
+	})
+}
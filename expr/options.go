@@ -0,0 +1,107 @@
+package expr
+
+import (
+	"fmt"
+	"reflect"
+
+	"go.chrisrx.dev/x/maps"
+	"go.chrisrx.dev/x/strings"
+)
+
+type Option func(*Expr)
+
+// Env sets the variables available to an expression. When used with
+// [Compile], the types of the values are also declared for type checking.
+func Env(env map[string]reflect.Value) Option {
+	return func(e *Expr) {
+		e.env = env
+		types := maps.Clone(e.types)
+		if types == nil {
+			types = make(map[string]reflect.Type, len(env))
+		}
+		for name, v := range env {
+			types[name] = v.Type()
+		}
+		e.types = types
+	}
+}
+
+// Declare declares a variable name and type that will be provided when
+// running a [Program]. This allows an expression to be compiled and type
+// checked before any values are available.
+func Declare(name string, t reflect.Type) Option {
+	return func(e *Expr) {
+		types := maps.Clone(e.types)
+		if types == nil {
+			types = make(map[string]reflect.Type)
+		}
+		types[name] = t
+		e.types = types
+	}
+}
+
+// WithFunc adds a builtin function, replacing any existing builtin with the
+// same name. The provided value must be a function returning a value, or a
+// value and an error.
+func WithFunc(name string, fn any) Option {
+	return func(e *Expr) {
+		rv := reflect.ValueOf(fn)
+		if rv.Kind() != reflect.Func || rv.Type().NumOut() == 0 {
+			e.err = fmt.Errorf("builtin %q must be a function with a result, received %T", name, fn)
+			return
+		}
+		if err := checkResults(rv.Type()); err != nil {
+			e.err = fmt.Errorf("builtin %q %w", name, err)
+			return
+		}
+		e.builtins = maps.Clone(e.builtins)
+		e.builtins[name] = rv
+	}
+}
+
+// WithPackage adds objects to a package that can be referenced with a
+// selector, e.g. "tenant.Lookup". If the package already exists, the objects
+// are added to the existing package. Objects can be functions or values.
+func WithPackage(name string, objects map[string]any) Option {
+	return func(e *Expr) {
+		members := maps.Clone(e.packages[name])
+		if members == nil {
+			members = make(map[string]reflect.Value, len(objects))
+		}
+		for k, v := range objects {
+			members[k] = reflect.ValueOf(v)
+		}
+		e.packages = maps.Clone(e.packages)
+		e.packages[name] = members
+	}
+}
+
+// WithoutBuiltins removes builtins so that they cannot be used in
+// expressions. Names can refer to a builtin function (e.g. "getenv"), an
+// object in a package (e.g. "fmt.Printf") or an entire package (e.g. "fmt").
+func WithoutBuiltins(names ...string) Option {
+	return func(e *Expr) {
+		e.builtins = maps.Clone(e.builtins)
+		e.packages = maps.Clone(e.packages)
+		for _, name := range names {
+			if pkg, obj, ok := strings.Cut(name, "."); ok {
+				// Unknown packages are ignored, rather than added without
+				// any members.
+				members, ok := e.packages[pkg]
+				if !ok {
+					continue
+				}
+				members = maps.Clone(members)
+				delete(members, obj)
+				delete(members, upper(obj))
+				e.packages[pkg] = members
+				continue
+			}
+			if _, ok := e.builtins[name]; ok {
+				delete(e.builtins, name)
+				continue
+			}
+			delete(e.packages, name)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package expr
+
+import (
+	"strings"
+	"testing"
+
+	"go.chrisrx.dev/x/assert"
+)
+
+func TestOptions(t *testing.T) {
+	t.Run("with func", func(t *testing.T) {
+		e := New(WithFunc("shout", func(s string) string {
+			return strings.ToUpper(s) + "!"
+		}))
+		v, err := e.Eval(`shout("hello")`)
+		assert.NoError(t, err)
+		assert.Equal(t, "HELLO!", v.String())
+
+		_, err = Eval(`shout("hello")`)
+		assert.Error(t, "undefined: shout", err, "builtins are not shared with other instances")
+
+		_, err = Eval(`x()`, WithFunc("x", 5))
+		assert.Error(t, `builtin "x" must be a function with a result, received int`, err)
+	})
+
+	t.Run("with package", func(t *testing.T) {
+		e := New(WithPackage("tenant", map[string]any{
+			"Lookup":  func(id int) string { return map[int]string{1: "acme"}[id] },
+			"Default": 1,
+		}))
+		v, err := e.Eval(`tenant.lookup(tenant.Default)`)
+		assert.NoError(t, err)
+		assert.Equal(t, "acme", v.String())
+
+		e = New(WithPackage("math", map[string]any{
+			"Double": func(f float64) float64 { return f * 2 },
+		}))
+		v, err = e.Eval(`math.Double(math.Round(2.4))`)
+		assert.NoError(t, err, "existing package members are kept")
+		assert.Equal(t, 4.0, v.Float())
+	})
+
+	t.Run("without builtins", func(t *testing.T) {
+		e := New(WithoutBuiltins("getenv", "fmt.Printf", "base64"))
+		_, err := e.Eval(`getenv("HOME")`)
+		assert.Error(t, "undefined: getenv", err)
+		_, err = e.Eval(`fmt.Printf("hi")`)
+		assert.Error(t, "cannot find object in package: fmt.Printf", err)
+		_, err = e.Eval(`base64.encode("data")`)
+		assert.Error(t, "undefined: base64", err)
+
+		v, err := e.Eval(`fmt.Sprint("hi")`)
+		assert.NoError(t, err)
+		assert.Equal(t, "hi", v.String())
+
+		_, err = Eval(`len(getenv("HOME")) >= 0`)
+		assert.NoError(t, err, "default builtins are not modified")
+	})
+
+	t.Run("without unknown builtins", func(t *testing.T) {
+		e := New(WithoutBuiltins("nosuch.x"))
+		_, err := e.Eval(`nosuch`)
+		assert.Error(t, "undefined: nosuch", err, "unknown packages aren't added")
+		_, err = e.Eval(`nosuch.x`)
+		assert.Error(t, "undefined: nosuch", err)
+
+		e = New(WithoutBuiltins("nosuch"))
+		_, err = e.Eval(`nosuch`)
+		assert.Error(t, "undefined: nosuch", err)
+		v, err := e.Eval(`upper("hi")`)
+		assert.NoError(t, err)
+		assert.Equal(t, "HI", v.String())
+	})
+}
@@ -0,0 +1,137 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+
+	"go.chrisrx.dev/x/strings"
+)
+
+// Program is a compiled expression that can be evaluated many times. The
+// expression is parsed and type checked once by [Compile], so running a
+// Program only has to evaluate the expression. A Program is safe for
+// concurrent use.
+type Program struct {
+	src  string
+	expr ast.Expr
+	e    *Expr
+	typ  reflect.Type
+	vars map[string]reflect.Type
+
+	builtins map[string]struct{}
+
+	// bindings are the statements of a script that are evaluated before
+	// the expression.
+	bindings []binding
+}
+
+// Compile parses an expression and checks it against the declared variables
+// and builtins. Undefined identifiers, calls with the wrong number of
+// arguments and operations on incompatible types are reported as errors here,
+// rather than when the expression is evaluated.
+//
+// Variables are declared with the [Env] or [Declare] options. Any values
+// provided with [Env] are only used for their types, the values used during
+// evaluation are passed to [Program.Run].
+func Compile(s string, opts ...Option) (*Program, error) {
+	return compile(s, New(opts...))
+}
+
+func compile(s string, e *Expr) (*Program, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	src, offsets := rewriteKeywords(strings.ReplaceAll(s, `'`, `"`))
+	fset := token.NewFileSet()
+	expr, err := parser.ParseExprFrom(fset, "", src, 0)
+	if err != nil {
+		return nil, syntaxError(s, err)
+	}
+	e.src = s
+	e.fset = fset
+	restoreKeywords(fset.File(expr.Pos()), expr, offsets)
+	c := &checker{
+		e:        e,
+		vars:     make(map[string]reflect.Type),
+		builtins: make(map[string]struct{}),
+	}
+	t, err := c.check(expr)
+	if err != nil {
+		return nil, err
+	}
+	return &Program{
+		src:      s,
+		expr:     expr,
+		e:        e,
+		typ:      t,
+		vars:     c.vars,
+		builtins: c.builtins,
+	}, nil
+}
+
+// MustCompile is a convenience function for calling [Compile] that panics if
+// an error is encountered.
+func MustCompile(s string, opts ...Option) *Program {
+	p, err := Compile(s, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// String returns the source of the compiled expression.
+func (p *Program) String() string {
+	return p.src
+}
+
+// Type returns the type of the value the expression evaluates to. A nil type
+// is returned when the type can only be known during evaluation, such as
+// when a value is held by an interface.
+func (p *Program) Type() reflect.Type {
+	return p.typ
+}
+
+// Run evaluates the compiled expression using the provided variables. Every
+// variable referenced by the expression must be present and be assignable to
+// the type it was declared with.
+func (p *Program) Run(env map[string]reflect.Value) (reflect.Value, error) {
+	return p.RunContext(context.Background(), env)
+}
+
+// RunContext evaluates the compiled expression like [Program.Run]. The
+// evaluation is stopped with a [*LimitError] if the context is done before
+// evaluation finishes. Runtime panics during evaluation are recovered and
+// returned as errors, so an expression can never crash the program.
+func (p *Program) RunContext(ctx context.Context, env map[string]reflect.Value) (_ reflect.Value, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("expr: panic during evaluation: %v", r)
+		}
+	}()
+	vars := make(map[string]reflect.Value, len(p.vars))
+	for name, t := range p.vars {
+		v, ok := env[name]
+		if !ok || !v.IsValid() {
+			return reflect.Value{}, fmt.Errorf("%w: %v", errUndefined, name)
+		}
+		if !v.Type().AssignableTo(t) {
+			return reflect.Value{}, fmt.Errorf("cannot use %v as %v value for variable %q", v.Type(), t, name)
+		}
+		vars[name] = v
+	}
+	e := *p.e
+	e.env = vars
+	defer e.start(ctx)()
+	for _, b := range p.bindings {
+		v, err := e.eval(b.expr)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		vars[b.name] = v
+	}
+	return e.eval(p.expr)
+}
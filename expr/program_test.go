@@ -0,0 +1,137 @@
+package expr
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.chrisrx.dev/x/assert"
+)
+
+func TestCompile(t *testing.T) {
+	t.Run("run many times", func(t *testing.T) {
+		p, err := Compile(`split_addr(self).port > 1024`, Declare("self", reflect.TypeFor[string]()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, reflect.TypeFor[bool](), p.Type())
+
+		for addr, expected := range map[string]bool{
+			":8080": true,
+			":443":  false,
+			":1025": true,
+		} {
+			v, err := p.Run(map[string]reflect.Value{
+				"self": reflect.ValueOf(addr),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, expected, v.Bool(), addr)
+		}
+	})
+
+	t.Run("result types", func(t *testing.T) {
+		cases := []struct {
+			input    string
+			expected reflect.Type
+		}{
+			{input: `1 + 2`, expected: reflect.TypeFor[int64]()},
+			{input: `1.5 * 2`, expected: reflect.TypeFor[float64]()},
+			{input: `"a" + "b"`, expected: reflect.TypeFor[string]()},
+			{input: `len("hello") > 4`, expected: reflect.TypeFor[bool]()},
+			{input: `now() + duration("1m")`, expected: reflect.TypeFor[time.Time]()},
+			{input: `time.Duration(5)`, expected: reflect.TypeFor[time.Duration]()},
+			{input: `Something{S: "testing"}`, expected: reflect.TypeFor[Something]()},
+			{input: `split("a,b", ",")[0]`, expected: reflect.TypeFor[string]()},
+			{input: `-1.5`, expected: reflect.TypeFor[float64]()},
+			{input: `-time.Minute`, expected: reflect.TypeFor[time.Duration]()},
+		}
+		for _, tc := range cases {
+			p, err := Compile(tc.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tc.expected, p.Type(), tc.input)
+		}
+	})
+
+	t.Run("methods", func(t *testing.T) {
+		p, err := Compile(`self.addr().port()`, Declare("self", reflect.TypeFor[server]()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, reflect.TypeFor[int](), p.Type())
+
+		p, err = Compile(`self.Addr().Lookup()`, Declare("self", reflect.TypeFor[*server]()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, reflect.TypeFor[string](), p.Type())
+
+		_, err = Compile(`self.Host.nope()`, Declare("self", reflect.TypeFor[*server]()))
+		assert.Error(t, `no field or method "nope" on expr.hostname`, err)
+
+		_, err = Compile(`self.addr().port(1)`, Declare("self", reflect.TypeFor[server]()))
+		assert.Error(t, `takes 0 args, received 1`, err)
+	})
+
+	t.Run("variadic without args", func(t *testing.T) {
+		for input, expected := range map[string]any{
+			`coalesce("a", coalesce())`: "a",
+			`rand() >= 0`:               true,
+			`sprintf("a")`:              "a",
+		} {
+			p, err := Compile(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v, err := p.Run(nil)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, v.Interface(), input)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		cases := []struct {
+			input    string
+			expected string
+		}{
+			{input: `foo + 1`, expected: "undefined: foo"},
+			{input: `split_addr("a", "b")`, expected: `func split_addr\(\) takes 1 args, received 2`},
+			{input: `upper(1.5)`, expected: "cannot convert: float64 -> string"},
+			{input: `"a" + 1`, expected: "operator \\+ on incompatible types"},
+			{input: `1 + "a"`, expected: "operator \\+ on incompatible types: int64 <> string"},
+			{input: `1 > "x"`, expected: "operator > on incompatible types: int64 <> string"},
+			{input: `len("a") == "1"`, expected: "operator == on incompatible types: int <> string"},
+			{input: `upper(1)`, expected: "cannot convert: int64 -> string"},
+			{input: `(1 == 1) < (2 == 2)`, expected: "operator < not defined for bool"},
+			{input: `!5`, expected: "operator ! not defined for int64"},
+			{input: `-"a"`, expected: "operator - not defined for string"},
+			{input: `^1.5`, expected: "operator \\^ not defined for float64"},
+			{input: `&1`, expected: "operator & not supported"},
+			{input: `now().nope()`, expected: `no field or method "nope" on time.Time`},
+			{input: `time.Nope`, expected: "cannot find object in package: time.Nope"},
+			{input: `Something{X: 1}`, expected: "unknown field X in struct literal"},
+			{input: `"abc"[1:]`, expected: "unsupported ast.Expr: \\*ast.SliceExpr"},
+			{input: `func(x int) bool { return "a" }`, expected: "cannot use string as bool value in return statement"},
+			{input: `func(x int) bool { return y > 0 }`, expected: "undefined: y"},
+			{input: `func(x int) bool { x++; return true }`, expected: "func literal body must be a single return statement"},
+		}
+		for _, tc := range cases {
+			_, err := Compile(tc.input)
+			assert.Error(t, tc.expected, err, tc.input)
+		}
+	})
+
+	t.Run("run with missing variable", func(t *testing.T) {
+		p := MustCompile(`len(name) > 0`, Declare("name", reflect.TypeFor[string]()))
+		_, err := p.Run(nil)
+		assert.Error(t, "undefined: name", err)
+
+		_, err = p.Run(map[string]reflect.Value{
+			"name": reflect.ValueOf(5),
+		})
+		assert.Error(t, `cannot use int as string value for variable "name"`, err)
+	})
+}
@@ -0,0 +1,215 @@
+package expr
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"go.chrisrx.dev/x/maps"
+	"go.chrisrx.dev/x/slices"
+	"go.chrisrx.dev/x/strings"
+)
+
+// Capability is a side effect that a builtin requires, such as writing to
+// standard output or reading environment variables. Builtins requiring a
+// capability are disabled by [Sandbox] unless the capability is allowed.
+type Capability string
+
+const (
+	// Stdout is required by builtins that write to standard output, e.g.
+	// "print" and "fmt.Printf".
+	Stdout Capability = "stdout"
+
+	// Environ is required by builtins that read environment variables, e.g.
+	// "getenv".
+	Environ Capability = "environ"
+
+	// Filesystem is required by builtins that inspect the filesystem, e.g.
+	// "getwd" and "tempdir".
+	Filesystem Capability = "filesystem"
+)
+
+// capabilities are the capabilities required by default builtins and package
+// objects.
+var capabilities = map[string]Capability{
+	"print":       Stdout,
+	"printf":      Stdout,
+	"println":     Stdout,
+	"fmt.Print":   Stdout,
+	"fmt.Printf":  Stdout,
+	"fmt.Println": Stdout,
+	"getenv":      Environ,
+	"getwd":       Filesystem,
+	"tempdir":     Filesystem,
+}
+
+const (
+	// DefaultMaxSteps is the maximum number of expression nodes evaluated
+	// when using [Sandbox] without setting [MaxSteps].
+	DefaultMaxSteps = 10_000
+
+	// DefaultMaxSize is the maximum length of strings, slices and maps
+	// produced when using [Sandbox] without setting [MaxSize].
+	DefaultMaxSize = 1 << 20
+
+	// DefaultTimeout is the maximum duration of an evaluation when using
+	// [Sandbox] without setting [Timeout].
+	DefaultTimeout = time.Second
+)
+
+var (
+	// ErrStepLimit is returned when the number of evaluated expression nodes
+	// exceeds the limit set by [MaxSteps].
+	ErrStepLimit = errors.New("step limit exceeded")
+
+	// ErrSizeLimit is returned when a string, slice or map value exceeds the
+	// limit set by [MaxSize].
+	ErrSizeLimit = errors.New("size limit exceeded")
+)
+
+// LimitError is returned when evaluating an expression exceeds a limit. It
+// wraps [ErrStepLimit], [ErrSizeLimit] or, for deadlines, the context error.
+type LimitError struct {
+	Limit int64
+	Err   error
+}
+
+func (e *LimitError) Error() string {
+	if e.Limit == 0 {
+		return fmt.Sprintf("expr: %v", e.Err)
+	}
+	return fmt.Sprintf("expr: %v (limit %d)", e.Err, e.Limit)
+}
+
+func (e *LimitError) Unwrap() error { return e.Err }
+
+// CapabilityError is returned when an expression references a builtin that
+// was disabled by [Sandbox].
+type CapabilityError struct {
+	Name       string
+	Capability Capability
+}
+
+func (e *CapabilityError) Error() string {
+	return fmt.Sprintf("expr: %s requires capability %q, which is not allowed", e.Name, e.Capability)
+}
+
+type limits struct {
+	maxSteps int64
+	maxSize  int64
+	timeout  time.Duration
+}
+
+// MaxSteps limits the number of expression nodes that can be evaluated. When
+// the limit is exceeded, evaluation stops with a [*LimitError] wrapping
+// [ErrStepLimit].
+func MaxSteps(n int64) Option {
+	return func(e *Expr) {
+		e.limits.maxSteps = n
+	}
+}
+
+// MaxSize limits the length of strings, slices and maps produced while
+// evaluating an expression. Values are checked after each expression node is
+// evaluated, so a single builtin call can still allocate a larger value
+// before it is rejected.
+func MaxSize(n int64) Option {
+	return func(e *Expr) {
+		e.limits.maxSize = n
+	}
+}
+
+// Timeout limits how long an expression can be evaluated. It applies in
+// addition to any deadline of the context passed to [Program.RunContext].
+// Builtin functions are not interrupted, the deadline is checked between
+// evaluating expression nodes.
+func Timeout(d time.Duration) Option {
+	return func(e *Expr) {
+		e.limits.timeout = d
+	}
+}
+
+// Sandbox bounds the evaluation of expressions that come from untrusted
+// sources. Builtins requiring a [Capability] that isn't explicitly allowed
+// are disabled, and referencing them is reported by [Compile] as a
+// [*CapabilityError]. Any limits not already set are set to
+// [DefaultMaxSteps], [DefaultMaxSize] and [DefaultTimeout].
+//
+// Methods on values passed in with [Env] are not restricted by a Sandbox.
+func Sandbox(allow ...Capability) Option {
+	return func(e *Expr) {
+		e.limits.maxSteps = cmp.Or(e.limits.maxSteps, DefaultMaxSteps)
+		e.limits.maxSize = cmp.Or(e.limits.maxSize, DefaultMaxSize)
+		e.limits.timeout = cmp.Or(e.limits.timeout, DefaultTimeout)
+
+		e.builtins = maps.Clone(e.builtins)
+		e.packages = maps.Clone(e.packages)
+		e.denied = maps.Clone(e.denied)
+		if e.denied == nil {
+			e.denied = make(map[string]Capability)
+		}
+		for name, c := range capabilities {
+			if slices.Contains(allow, c) {
+				continue
+			}
+			e.denied[name] = c
+			if pkg, obj, ok := strings.Cut(name, "."); ok {
+				members := maps.Clone(e.packages[pkg])
+				delete(members, obj)
+				e.packages[pkg] = members
+				continue
+			}
+			delete(e.builtins, name)
+		}
+	}
+}
+
+// state is the state of a single evaluation. It is shared by copies of the
+// Expr made while evaluating, e.g. for the scope of a function literal.
+type state struct {
+	ctx   context.Context
+	steps int64
+}
+
+// start prepares the Expr for evaluation, returning a function that must be
+// called when evaluation is done.
+func (e *Expr) start(ctx context.Context) context.CancelFunc {
+	cancel := context.CancelFunc(func() {})
+	if e.limits.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, e.limits.timeout)
+	}
+	e.state = &state{ctx: ctx}
+	return cancel
+}
+
+// step is called before evaluating each expression node.
+func (e *Expr) step() error {
+	if e.state == nil {
+		return nil
+	}
+	e.state.steps++
+	if e.limits.maxSteps > 0 && e.state.steps > e.limits.maxSteps {
+		return &LimitError{Limit: e.limits.maxSteps, Err: ErrStepLimit}
+	}
+	if err := e.state.ctx.Err(); err != nil {
+		return &LimitError{Err: err}
+	}
+	return nil
+}
+
+// checkSize is called with the value produced by each expression node.
+func (e *Expr) checkSize(v reflect.Value) error {
+	if e.limits.maxSize <= 0 || !v.IsValid() {
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		if int64(v.Len()) > e.limits.maxSize {
+			return &LimitError{Limit: e.limits.maxSize, Err: ErrSizeLimit}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package expr
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.chrisrx.dev/x/assert"
+	"go.chrisrx.dev/x/errors"
+)
+
+func TestSandbox(t *testing.T) {
+	t.Run("capabilities", func(t *testing.T) {
+		for _, input := range []string{
+			`getenv("HOME")`,
+			`len(getwd()) > 0`,
+			`printf("hi")`,
+			`fmt.Println("hi")`,
+			`fmt.println("hi")`,
+		} {
+			_, err := Eval(input, Sandbox())
+			capErr, ok := errors.As[*CapabilityError](err)
+			if !ok {
+				t.Fatalf("%s: expected *CapabilityError, received %v", input, err)
+			}
+			assert.Error(t, "requires capability", capErr, input)
+		}
+
+		v, err := Eval(`len(getenv("HOME")) >= 0`, Sandbox(Environ))
+		assert.NoError(t, err, "allowed capability")
+		assert.Equal(t, true, v.Bool())
+
+		v, err = Eval(`fmt.Sprint("hi")`, Sandbox())
+		assert.NoError(t, err, "builtins without side effects")
+		assert.Equal(t, "hi", v.String())
+	})
+
+	t.Run("max steps", func(t *testing.T) {
+		_, err := Eval(`1 + 2 + 3 + 4 + 5`, MaxSteps(5))
+		assert.Error(t, ErrStepLimit, err)
+		limitErr, ok := errors.As[*LimitError](err)
+		if !ok {
+			t.Fatalf("expected *LimitError, received %T", err)
+		}
+		assert.Equal(t, int64(5), limitErr.Limit)
+
+		_, err = Eval(`1 + 2 + 3 + 4 + 5`, MaxSteps(9))
+		assert.NoError(t, err)
+	})
+
+	t.Run("max size", func(t *testing.T) {
+		_, err := Eval(`"abc" + "def"`, MaxSize(5))
+		assert.Error(t, ErrSizeLimit, err)
+
+		_, err = Eval(`split(self, ",")`, MaxSize(2), Env(map[string]reflect.Value{
+			"self": reflect.ValueOf("a,b,c"),
+		}))
+		assert.Error(t, ErrSizeLimit, err)
+
+		_, err = Eval(`"abc" + "de"`, MaxSize(5))
+		assert.NoError(t, err)
+	})
+
+	t.Run("divide by zero", func(t *testing.T) {
+		for _, input := range []string{`1 / 0`, `1 % 0`, `1 / (2 - 2)`, `self / 0`} {
+			_, err := Eval(input, Sandbox(), Env(map[string]reflect.Value{
+				"self": reflect.ValueOf(uint(1)),
+			}))
+			assert.Error(t, ErrDivideByZero, err, input)
+			if _, ok := errors.As[*Error](err); !ok {
+				t.Fatalf("%s: expected *Error, received %T", input, err)
+			}
+		}
+
+		v, err := Eval(`1.0 / 0.0 > 0`, Sandbox())
+		assert.NoError(t, err, "float division")
+		assert.Equal(t, true, v.Bool())
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		p := MustCompile(`1 + 1`)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := p.RunContext(ctx, nil)
+		assert.Error(t, context.Canceled, err)
+
+		p = MustCompile(`sleep() + 1`, WithFunc("sleep", func() int {
+			time.Sleep(10 * time.Millisecond)
+			return 1
+		}), Timeout(time.Millisecond))
+		_, err = p.Run(nil)
+		assert.Error(t, context.DeadlineExceeded, err)
+	})
+}
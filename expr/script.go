@@ -0,0 +1,227 @@
+package expr
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"reflect"
+
+	"go.chrisrx.dev/x/slices"
+	"go.chrisrx.dev/x/strings"
+)
+
+// A script is a sequence of statements separated by semicolons or newlines.
+// Every statement but the last is a binding of a name to the value of an
+// expression, and the last statement is the expression the script evaluates
+// to:
+//
+//	host := getenv("PGHOST")
+//	port := coalesce(getenv("PGPORT"), "5432")
+//	sprintf("postgres://%s:%s", host, port)
+//
+// Bound names can be used by the statements that follow them, and shadow
+// variables and builtins of the same name.
+
+// binding is a statement of a script that binds a name to the value of an
+// expression.
+type binding struct {
+	name string
+	expr ast.Expr
+}
+
+// CompileScript parses and checks a script. See [Compile].
+func CompileScript(s string, opts ...Option) (*Program, error) {
+	return compileScript(s, New(opts...))
+}
+
+// CompileScript compiles a script using the builtins and packages of this
+// Expr. See [CompileScript].
+func (e *Expr) CompileScript(s string, opts ...Option) (*Program, error) {
+	return compileScript(s, e.with(opts))
+}
+
+// EvalScript compiles and evaluates a script. It is a convenience function for
+// calling [CompileScript] and [Program.Run] for scripts that are only
+// evaluated once. A script consisting of a single expression is evaluated the
+// same as with [Eval].
+func EvalScript(s string, opts ...Option) (reflect.Value, error) {
+	return New(opts...).EvalScript(s)
+}
+
+// EvalScript compiles and evaluates a script using the builtins and packages
+// of this Expr. See [EvalScript].
+func (e *Expr) EvalScript(s string, opts ...Option) (reflect.Value, error) {
+	p, err := e.CompileScript(s, opts...)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return p.Run(p.e.env)
+}
+
+// IsScript returns whether s is a script with more than one statement or a
+// binding, rather than a single expression. Statements are split the same as
+// by [CompileScript], so semicolons in string literals or inside parentheses
+// don't make an expression a script.
+func IsScript(s string) bool {
+	src, _ := rewriteKeywords(strings.ReplaceAll(s, `'`, `"`))
+	stmts := splitStatements(src)
+	return len(stmts) > 1 || len(stmts) == 1 && stmts[0].name != ""
+}
+
+func compileScript(s string, e *Expr) (*Program, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	src, offsets := rewriteKeywords(strings.ReplaceAll(s, `'`, `"`))
+	stmts := splitStatements(src)
+	if len(stmts) == 0 {
+		return nil, &Error{Src: s, Line: 1, Column: 1, Err: fmt.Errorf("empty script")}
+	}
+	var (
+		bindings []binding
+		result   ast.Expr
+	)
+	// Every statement is added to the same file set as a file of its own, so
+	// that the position of any node can be mapped back to the statement it
+	// came from.
+	fset := token.NewFileSet()
+	e.src = s
+	e.fset = fset
+	for i, stmt := range stmts {
+		if stmt.name == "" && i < len(stmts)-1 {
+			return nil, syntaxAt(s, stmt.pos, fmt.Errorf("expression result is not used, expected name := value"))
+		}
+		if stmt.name != "" && i == len(stmts)-1 {
+			return nil, syntaxAt(s, stmt.pos, fmt.Errorf("script must end with an expression"))
+		}
+
+		// Each statement is parsed from a copy of the source up to the end of
+		// the statement, with the preceding statements blanked out, so that
+		// offsets within its file are relative to the whole script.
+		expr, err := parser.ParseExprFrom(fset, "", blank(src[:stmt.end], stmt.start), 0)
+		if err != nil {
+			return nil, syntaxError(s, err)
+		}
+		restoreKeywords(fset.File(expr.Pos()), expr, offsets)
+		if stmt.name == "" {
+			result = expr
+			continue
+		}
+		if slices.ContainsFunc(bindings, func(b binding) bool { return b.name == stmt.name }) {
+			return nil, syntaxAt(s, stmt.pos, fmt.Errorf("no new variables on left side of :="))
+		}
+		bindings = append(bindings, binding{name: stmt.name, expr: expr})
+	}
+	c := &checker{
+		e:        e,
+		vars:     make(map[string]reflect.Type),
+		builtins: make(map[string]struct{}),
+		scope:    make(map[string]reflect.Type),
+	}
+	for _, b := range bindings {
+		t, err := c.check(b.expr)
+		if err != nil {
+			return nil, err
+		}
+		c.scope[b.name] = t
+	}
+	t, err := c.check(result)
+	if err != nil {
+		return nil, err
+	}
+	return &Program{
+		src:      s,
+		expr:     result,
+		e:        e,
+		typ:      t,
+		vars:     c.vars,
+		builtins: c.builtins,
+		bindings: bindings,
+	}, nil
+}
+
+// statement is the location of a statement in the source of a script. The
+// name is set for bindings, in which case start is the offset of the value.
+type statement struct {
+	name       string
+	pos        int
+	start, end int
+}
+
+// splitStatements splits a script into statements, using the same rules as Go
+// for inserting semicolons at the end of lines. Semicolons inside
+// parentheses, brackets and braces don't end a statement.
+func splitStatements(src string) []statement {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+	var s scanner.Scanner
+	s.Init(file, []byte(src), nil, 0)
+
+	var (
+		stmts []statement
+		depth int
+		n     int
+		pos   int
+		start int
+		ident string
+		name  string
+	)
+	for {
+		p, tok, lit := s.Scan()
+		offset := file.Offset(p)
+		switch tok {
+		case token.LPAREN, token.LBRACK, token.LBRACE:
+			depth++
+		case token.RPAREN, token.RBRACK, token.RBRACE:
+			depth--
+		}
+		if depth == 0 && (tok == token.SEMICOLON || tok == token.EOF) {
+			if n > 0 {
+				stmts = append(stmts, statement{name: name, pos: pos, start: start, end: offset})
+			}
+			if tok == token.EOF {
+				return stmts
+			}
+			n, ident, name = 0, "", ""
+			continue
+		}
+		n++
+		switch {
+		case n == 1:
+			pos, start = offset, offset
+			if tok == token.IDENT {
+				ident = lit
+			}
+		case n == 2 && tok == token.DEFINE && ident != "":
+			start = offset + len(token.DEFINE.String())
+			name = ident
+		}
+	}
+}
+
+// blank returns the source with everything before start replaced with spaces.
+// Newlines are kept so that lines and columns don't change.
+func blank(src string, start int) string {
+	b := []byte(src)
+	for i := range b[:start] {
+		if b[i] != '\n' {
+			b[i] = ' '
+		}
+	}
+	return string(b)
+}
+
+// syntaxAt returns an [*Error] for the provided offset of the source.
+func syntaxAt(src string, offset int, err error) error {
+	line := strings.Count(src[:offset], "\n") + 1
+	column := offset - (strings.LastIndexByte(src[:offset], '\n') + 1) + 1
+	return &Error{
+		Src:    src,
+		Offset: offset,
+		Line:   line,
+		Column: column,
+		Err:    err,
+	}
+}
@@ -0,0 +1,121 @@
+package expr
+
+import (
+	"reflect"
+	"testing"
+
+	"go.chrisrx.dev/x/assert"
+	"go.chrisrx.dev/x/errors"
+)
+
+func TestEvalScript(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    string
+		env      map[string]reflect.Value
+		expected any
+	}{
+		{name: "single expression", input: `1 + 2`, expected: int64(3)},
+		{name: "semicolons", input: `x := 2; y := x * 3; x + y`, expected: int64(8)},
+		{
+			name: "newlines",
+			input: `
+				host := "localhost"
+				port := 5432
+				sprintf("postgres://%s:%d", host, port)
+			`,
+			expected: "postgres://localhost:5432",
+		},
+		{
+			name: "multi-line expressions",
+			input: `
+				ports := filter(self, func(p) {
+					return p > 1024
+				})
+				len(ports)
+			`,
+			env:      map[string]reflect.Value{"self": reflect.ValueOf([]int{80, 8080, 9090})},
+			expected: 2,
+		},
+		{name: "shadow builtin", input: `len := 5; len + 1`, expected: int64(6)},
+		{name: "shadow variable", input: `self := self * 2; self`, env: map[string]reflect.Value{"self": reflect.ValueOf(21)}, expected: int64(42)},
+		{name: "single quotes", input: `name := 'world'; 'hello ' + name`, expected: "hello world"},
+		{name: "map keyword", input: `xs := map(split("a,b", ","), func(s) { return upper(s) }); xs[1]`, expected: "B"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			v, err := EvalScript(tc.input, Env(tc.env))
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tc.expected, v.Interface())
+		})
+	}
+
+	t.Run("compile once", func(t *testing.T) {
+		p, err := CompileScript(`
+			addr := split_addr(self)
+			addr.port > 1024 && addr.host != ""
+		`, Declare("self", reflect.TypeFor[string]()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, reflect.TypeFor[bool](), p.Type())
+		for addr, expected := range map[string]bool{
+			"localhost:8080": true,
+			"localhost:80":   false,
+			":8080":          false,
+		} {
+			v, err := p.Run(map[string]reflect.Value{"self": reflect.ValueOf(addr)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, expected, v.Bool(), addr)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		cases := []struct {
+			input  string
+			err    string
+			line   int
+			column int
+		}{
+			{input: ``, err: "empty script", line: 1, column: 1},
+			{input: "x := 1\nx + y", err: "2:5: undefined: y", line: 2, column: 5},
+			{input: "x := 1\ny := 2", err: "2:1: script must end with an expression", line: 2, column: 1},
+			{input: "1 + 2; 3", err: "1:1: expression result is not used", line: 1, column: 1},
+			{input: "x := 1; x := 2; x", err: "1:9: no new variables on left side of :=", line: 1, column: 9},
+			{input: "x := 1 +; x", err: "1:9: expected operand", line: 1, column: 9},
+			{input: "x := 'a'\nx + 1", err: "2:1: invalid operation", line: 2, column: 1},
+			{input: "x := atoi('a')\nx", err: "1:6: strconv.Atoi", line: 1, column: 6},
+			{input: "x := y + 1\nz := 2\nx + z", err: "1:6: undefined: y", line: 1, column: 6},
+		}
+		for _, tc := range cases {
+			_, err := EvalScript(tc.input)
+			assert.Error(t, tc.err, err, tc.input)
+			exprErr, ok := errors.As[*Error](err)
+			if !ok {
+				t.Fatalf("expected *Error, received %T", err)
+			}
+			assert.Equal(t, tc.line, exprErr.Line, tc.input)
+			assert.Equal(t, tc.column, exprErr.Column, tc.input)
+		}
+	})
+
+	t.Run("is script", func(t *testing.T) {
+		for input, expected := range map[string]bool{
+			`x := 1; x`:                         true,
+			"x := 1\nx":                         true,
+			`1; 2`:                              true,
+			`x := 1`:                            true,
+			`upper("a")`:                        false,
+			`"a;b"`:                             false,
+			`'x := 1'`:                          false,
+			`split("a;b", ";")[0]`:              false,
+			`func(x int) bool { return x > 0 }`: false,
+		} {
+			assert.Equal(t, expected, IsScript(input), input)
+		}
+	})
+}
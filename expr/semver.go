@@ -0,0 +1,112 @@
+package expr
+
+import (
+	"cmp"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// semver is a semantic version, as described by https://semver.org. It is
+// returned by the "semver" builtin so that the components can be used in
+// expressions, e.g. "semver(self).major >= 2".
+type semver struct {
+	Major, Minor, Patch int
+	Prerelease          string
+	Build               string
+}
+
+// parseSemver parses a semantic version. A leading "v" is allowed, and the
+// minor and patch versions can be left out, e.g. "v1.2" is the same as
+// "1.2.0".
+func parseSemver(s string) (semver, error) {
+	var v semver
+	rest := strings.TrimPrefix(s, "v")
+	rest, v.Build, _ = strings.Cut(rest, "+")
+	rest, v.Prerelease, _ = strings.Cut(rest, "-")
+	parts := strings.Split(rest, ".")
+	if len(parts) > 3 {
+		return semver{}, fmt.Errorf("invalid semantic version: %q", s)
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || (len(part) > 1 && part[0] == '0') {
+			return semver{}, fmt.Errorf("invalid semantic version: %q", s)
+		}
+		switch i {
+		case 0:
+			v.Major = n
+		case 1:
+			v.Minor = n
+		case 2:
+			v.Patch = n
+		}
+	}
+	return v, nil
+}
+
+// Compare compares the version with another version, returning -1, 0 or +1.
+// Build metadata is ignored.
+func (v semver) Compare(other string) (int, error) {
+	w, err := parseSemver(other)
+	if err != nil {
+		return 0, err
+	}
+	return v.compare(w), nil
+}
+
+func (v semver) compare(w semver) int {
+	if c := cmp.Or(
+		cmp.Compare(v.Major, w.Major),
+		cmp.Compare(v.Minor, w.Minor),
+		cmp.Compare(v.Patch, w.Patch),
+	); c != 0 {
+		return c
+	}
+	// A version without a prerelease has a higher precedence than one with
+	// a prerelease.
+	switch {
+	case v.Prerelease == w.Prerelease:
+		return 0
+	case v.Prerelease == "":
+		return 1
+	case w.Prerelease == "":
+		return -1
+	}
+	a, b := strings.Split(v.Prerelease, "."), strings.Split(w.Prerelease, ".")
+	for i := range min(len(a), len(b)) {
+		if c := comparePrerelease(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(a), len(b))
+}
+
+// comparePrerelease compares prerelease identifiers. Numeric identifiers are
+// compared numerically and have a lower precedence than alphanumeric
+// identifiers.
+func comparePrerelease(a, b string) int {
+	x, xerr := strconv.Atoi(a)
+	y, yerr := strconv.Atoi(b)
+	switch {
+	case xerr == nil && yerr == nil:
+		return cmp.Compare(x, y)
+	case xerr == nil:
+		return -1
+	case yerr == nil:
+		return 1
+	default:
+		return cmp.Compare(a, b)
+	}
+}
+
+func (v semver) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
@@ -101,3 +101,82 @@ func Indirect(rv reflect.Value, to reflect.Type) reflect.Value {
 	}
 	return rv
 }
+
+// IsPromoted returns whether a method of a struct, or of a pointer to it, is
+// promoted from an embedded field rather than declared on the struct. It is
+// decided from the method sets of the embedded fields, which already include
+// the methods promoted into them: the method is promoted if an embedded field
+// adds a method with the same name and signature to the method set it is
+// found in. A method declared on the struct with the same name, signature and
+// receiver as a method of an embedded field can't be told apart from the
+// promoted method with reflection, so it is also reported as promoted.
+func IsPromoted(rt reflect.Type, name string) bool {
+	if rt.Kind() != reflect.Struct {
+		return false
+	}
+	// Methods of the value are in the method sets of both the value and the
+	// pointer, so the value is checked first.
+	m, ok := rt.MethodByName(name)
+	byValue := ok
+	if !ok {
+		m, ok = reflect.PointerTo(rt).MethodByName(name)
+	}
+	if !ok {
+		return false
+	}
+	for i := range rt.NumField() {
+		field := rt.Field(i)
+		if !field.Anonymous {
+			continue
+		}
+		// An embedded value only adds the methods of its pointer to the
+		// method set of a pointer to the struct.
+		ft := field.Type
+		if !byValue && ft.Kind() != reflect.Pointer {
+			ft = reflect.PointerTo(ft)
+		}
+		if em, ok := ft.MethodByName(name); ok && sameSignature(m.Type, em.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameSignature returns whether two method types have the same parameters
+// and results, ignoring the receiver.
+func sameSignature(a, b reflect.Type) bool {
+	if a.NumIn() != b.NumIn() || a.NumOut() != b.NumOut() || a.IsVariadic() != b.IsVariadic() {
+		return false
+	}
+	for i := 1; i < a.NumIn(); i++ {
+		if a.In(i) != b.In(i) {
+			return false
+		}
+	}
+	for i := range a.NumOut() {
+		if a.Out(i) != b.Out(i) {
+			return false
+		}
+	}
+	return true
+}
+
+// UpdateMapIndex calls fn with a settable copy of the value of key in the map
+// rv, or the zero value if the key isn't set, then sets the key to the copy.
+// Map values aren't addressable, so this is the only way to change them in
+// place. The map is allocated if it is nil, and nothing is set if fn returns
+// an error.
+func UpdateMapIndex(rv, key reflect.Value, fn func(elem reflect.Value) error) error {
+	elem := reflect.New(rv.Type().Elem()).Elem()
+	if v := rv.MapIndex(key); v.IsValid() {
+		elem.Set(v)
+	}
+	if err := fn(elem); err != nil {
+		return err
+	}
+	if rv.IsNil() {
+		rv.Set(reflect.MakeMap(rv.Type()))
+	}
+	rv.SetMapIndex(key, elem)
+	return nil
+}
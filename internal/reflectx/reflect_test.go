@@ -0,0 +1,60 @@
+package reflectx_test
+
+import (
+	"reflect"
+	"testing"
+
+	"go.chrisrx.dev/x/assert"
+	"go.chrisrx.dev/x/internal/reflectx"
+)
+
+type base struct{}
+
+func (base) Validate() error { return nil }
+func (*base) Setup()         {}
+
+type promoted struct {
+	base
+}
+
+type promotedPointer struct {
+	*base
+}
+
+type declared struct {
+	base
+}
+
+// Setup has a different signature than the Setup of base.
+func (declared) Setup() error { return nil }
+
+// Default isn't declared by base.
+func (*declared) Default() {}
+
+type nested struct {
+	promoted
+}
+
+func TestIsPromoted(t *testing.T) {
+	cases := []struct {
+		rt       reflect.Type
+		name     string
+		expected bool
+	}{
+		{reflect.TypeFor[promoted](), "Validate", true},
+		{reflect.TypeFor[promoted](), "Setup", true},
+		{reflect.TypeFor[promotedPointer](), "Validate", true},
+		{reflect.TypeFor[promotedPointer](), "Setup", true},
+		{reflect.TypeFor[declared](), "Validate", true},
+		{reflect.TypeFor[declared](), "Setup", false},
+		{reflect.TypeFor[declared](), "Default", false},
+		{reflect.TypeFor[nested](), "Validate", true},
+		{reflect.TypeFor[base](), "Validate", false},
+		{reflect.TypeFor[base](), "Setup", false},
+		{reflect.TypeFor[promoted](), "Missing", false},
+		{reflect.TypeFor[*promoted](), "Validate", false},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.expected, reflectx.IsPromoted(tc.rt, tc.name), tc.rt.String()+"."+tc.name)
+	}
+}
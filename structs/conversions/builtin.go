@@ -27,22 +27,42 @@ func init() {
 		return time.ParseDuration(s)
 	})
 
+	convert.Register(func(d time.Duration, opts ...convert.Option) (string, error) {
+		return d.String(), nil
+	})
+
 	convert.Register(func(s string, opts ...convert.Option) (*url.URL, error) {
 		return url.Parse(s)
 	})
 
+	convert.Register(func(u *url.URL, opts ...convert.Option) (string, error) {
+		return u.String(), nil
+	})
+
 	convert.Register(func(s string, opts ...convert.Option) ([]byte, error) {
 		return []byte(s), nil
 	})
 
+	convert.Register(func(b []byte, opts ...convert.Option) (string, error) {
+		return string(b), nil
+	})
+
 	convert.Register(func(s string, opts ...convert.Option) (net.HardwareAddr, error) {
 		return net.HardwareAddr(s), nil
 	})
 
+	convert.Register(func(addr net.HardwareAddr, opts ...convert.Option) (string, error) {
+		return string(addr), nil
+	})
+
 	convert.Register(func(s string, opts ...convert.Option) (net.IP, error) {
 		return net.ParseIP(s), nil
 	})
 
+	convert.Register(func(ip net.IP, opts ...convert.Option) (string, error) {
+		return ip.String(), nil
+	})
+
 	convert.Register(func(s string, opts ...convert.Option) (*rsa.PublicKey, error) {
 		pub, err := loadPublicKey([]byte(s))
 		if err != nil {
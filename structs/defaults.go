@@ -4,9 +4,33 @@ import (
 	"fmt"
 	"reflect"
 
+	"go.chrisrx.dev/x/internal/reflectx"
 	"go.chrisrx.dev/x/must"
 )
 
+// DefaultsFunc is implemented by types that compute their defaults in code.
+// The Default method is called on each struct by [Defaults] before the
+// default tags of its fields are applied, so the fields it sets take
+// precedence over the tags. It is also called by env.Parse.
+type DefaultsFunc interface {
+	Default()
+}
+
+type defaultsOptions struct {
+	SkipNilPointers bool
+}
+
+type DefaultsOption func(*defaultsOptions)
+
+// SkipNilPointers is an option for [Defaults] that leaves nil pointer fields
+// as nil, unless the field has a default. Without it, nil pointer fields are
+// allocated so that defaults are applied to the value they point to.
+func SkipNilPointers() DefaultsOption {
+	return func(o *defaultsOptions) {
+		o.SkipNilPointers = true
+	}
+}
+
 // Defaults applys any defaults defined in struct tags to fields. Default
 // values are only applied to fields that have a `default` struct tag. It
 // behaves differently depending upon the type and value it is given:
@@ -18,18 +42,23 @@ import (
 //
 // When given a struct, defaults are applied in the same way as with the struct
 // pointer, but to a newly constructed struct, which is also returned.
-func Defaults[T any](v T) T {
+//
+// Nil pointer fields are allocated, unless the [SkipNilPointers] option is
+// used. Defaults are also applied to structs in existing elements of slices,
+// arrays and maps, but nil elements are left as nil. Structs implementing
+// [DefaultsFunc] have their Default method called.
+func Defaults[T any](v T, opts ...DefaultsOption) T {
 	rv := reflect.ValueOf(v)
 	if rv.Kind() == reflect.Pointer {
 		if rv.IsValid() && rv.IsNil() {
 			panic("value provided to Defaults must be initialized")
 		}
-		if err := TryDefaults(v); err != nil {
+		if err := TryDefaults(v, opts...); err != nil {
 			panic(err)
 		}
 		return v
 	}
-	if err := TryDefaults(&v); err != nil {
+	if err := TryDefaults(&v, opts...); err != nil {
 		panic(err)
 	}
 	return v
@@ -38,15 +67,17 @@ func Defaults[T any](v T) T {
 // DefaultsFor applies defaults defined in struct tags to the struct or struct
 // pointer specified in the type paramater. It works the same as [Defaults],
 // but only requires a type and constructs a new value in all cases.
-func DefaultsFor[T any]() T {
+func DefaultsFor[T any](opts ...DefaultsOption) T {
 	var v T
-	if err := TryDefaults(&v); err != nil {
+	if err := TryDefaults(&v, opts...); err != nil {
 		panic(err)
 	}
 	return v
 }
 
-func TryDefaults(v any) error {
+// TryDefaults applies defaults the same as [Defaults], but returns an error
+// instead of panicking.
+func TryDefaults(v any, opts ...DefaultsOption) error {
 	rv := reflect.ValueOf(v)
 	if rv.Kind() != reflect.Pointer {
 		return fmt.Errorf("must provide a struct pointer, received %T", v)
@@ -61,34 +92,93 @@ func TryDefaults(v any) error {
 		return fmt.Errorf("must provide a struct pointer, received %T", v)
 	}
 
-	for i := range rv.NumField() {
-		if err := setDefault(rv.Field(i), Field(rv.Type().Field(i))); err != nil {
+	o := &defaultsOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o.setStruct(rv)
+}
+
+func (o *defaultsOptions) setStruct(rv reflect.Value) error {
+	if v, ok := reflectx.Interface(rv).(DefaultsFunc); ok && !reflectx.IsPromoted(rv.Type(), "Default") {
+		v.Default()
+	}
+	for _, field := range planFor(rv.Type()).fields {
+		if err := o.setDefault(rv.Field(field.index), field); err != nil {
 			return err
 		}
 	}
-
 	return nil
 }
 
-func setDefault(rv reflect.Value, field Field) error {
+func (o *defaultsOptions) setDefault(rv reflect.Value, field planField) error {
 	switch {
 	case rv.Kind() == reflect.Pointer:
 		if rv.IsNil() {
+			if o.SkipNilPointers && !field.hasDefault {
+				return nil
+			}
 			// A nil pointer must have the underlying type initialized to be
 			// settable.
 			rv.Set(reflect.New(rv.Type().Elem()))
 		}
-		return setDefault(reflect.Indirect(rv), field)
+		return o.setDefault(reflect.Indirect(rv), field)
 	case HasConversion(rv), IsWellKnown(rv):
-		return must.Get1(field.SetDefault(rv))
+		return o.setField(rv, field)
 	case rv.Kind() == reflect.Struct:
-		for i := range rv.NumField() {
-			if err := setDefault(rv.Field(i), Field(rv.Type().Field(i))); err != nil {
+		return o.setStruct(rv)
+	default:
+		if err := o.setField(rv, field); err != nil {
+			return err
+		}
+		return o.setElems(rv)
+	}
+}
+
+// setField sets the default value of a field, if it has one.
+func (o *defaultsOptions) setField(rv reflect.Value, field planField) error {
+	if !field.hasDefault {
+		return nil
+	}
+	return must.Get1(field.tags.setDefault(rv))
+}
+
+// setElems applies defaults to the structs in the elements of a slice, array
+// or map.
+func (o *defaultsOptions) setElems(rv reflect.Value) error {
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := range rv.Len() {
+			if err := o.setElem(rv.Index(i)); err != nil {
 				return err
 			}
 		}
-		return nil
+	case reflect.Map:
+		for _, key := range rv.MapKeys() {
+			if err := reflectx.UpdateMapIndex(rv, key, o.setElem); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (o *defaultsOptions) setElem(rv reflect.Value) error {
+	switch {
+	case rv.Kind() == reflect.Pointer:
+		if rv.IsNil() {
+			return nil
+		}
+		return o.setElem(rv.Elem())
+	case isNested(rv.Type()):
+		return o.setStruct(rv)
 	default:
-		return must.Get1(field.SetDefault(rv))
+		return o.setElems(rv)
 	}
 }
+
+// isNested returns whether a type is a struct that has defaults applied to
+// its fields, rather than a value with a conversion, like time.Time.
+func isNested(rt reflect.Type) bool {
+	return planFor(rt).nested
+}
@@ -6,6 +6,7 @@ import (
 
 	"go.chrisrx.dev/x/assert"
 	"go.chrisrx.dev/x/env/testdata/pg"
+	"go.chrisrx.dev/x/ptr"
 	"go.chrisrx.dev/x/structs"
 )
 
@@ -58,6 +59,92 @@ func TestDefaults(t *testing.T) {
 	})
 }
 
+type defaultsBackend struct {
+	Host    string        `default:"localhost"`
+	Port    int           `default:"8080"`
+	Timeout time.Duration `default:"5s"`
+	Weight  *int          `default:"1"`
+	Labels  *string
+}
+
+type defaultsConfig struct {
+	Name     string
+	Backends []defaultsBackend
+	Replicas []*defaultsBackend
+	Regions  map[string]defaultsBackend
+	Zones    map[string]*defaultsBackend
+	Primary  *defaultsBackend
+	Groups   [][]defaultsBackend
+}
+
+func (c *defaultsConfig) Default() {
+	c.Name = "computed"
+}
+
+type defaultsCounter struct {
+	Port int `default:"80"`
+}
+
+func (c *defaultsCounter) Default() {
+	if c.Port == 0 {
+		c.Port = 443
+	}
+}
+
+func TestDefaultsElements(t *testing.T) {
+	backend := defaultsBackend{
+		Host:    "localhost",
+		Port:    8080,
+		Timeout: 5 * time.Second,
+		Weight:  ptr.To(1),
+		Labels:  ptr.To(""),
+	}
+
+	t.Run("", func(t *testing.T) {
+		cfg := structs.Defaults(defaultsConfig{
+			Backends: []defaultsBackend{{Host: "a"}, {}},
+			Replicas: []*defaultsBackend{{Port: 9090}, nil},
+			Regions:  map[string]defaultsBackend{"us": {}},
+			Zones:    map[string]*defaultsBackend{"a": {}, "b": nil},
+			Groups:   [][]defaultsBackend{{{}}},
+		})
+
+		assert.Equal(t, "computed", cfg.Name)
+		assert.Equal(t, []defaultsBackend{
+			{Host: "a", Port: 8080, Timeout: 5 * time.Second, Weight: ptr.To(1), Labels: ptr.To("")},
+			backend,
+		}, cfg.Backends)
+		assert.Equal(t, []*defaultsBackend{
+			{Host: "localhost", Port: 9090, Timeout: 5 * time.Second, Weight: ptr.To(1), Labels: ptr.To("")},
+			nil,
+		}, cfg.Replicas)
+		assert.Equal(t, map[string]defaultsBackend{"us": backend}, cfg.Regions)
+		assert.Equal(t, map[string]*defaultsBackend{"a": &backend, "b": nil}, cfg.Zones)
+		assert.Equal(t, [][]defaultsBackend{{backend}}, cfg.Groups)
+		assert.Equal(t, &backend, cfg.Primary)
+	})
+
+	t.Run("skip nil pointers", func(t *testing.T) {
+		cfg := structs.Defaults(defaultsConfig{}, structs.SkipNilPointers())
+		assert.Equal(t, (*defaultsBackend)(nil), cfg.Primary)
+
+		// Only pointers with a default are allocated.
+		cfg = structs.Defaults(defaultsConfig{Primary: &defaultsBackend{}}, structs.SkipNilPointers())
+		assert.Equal(t, &defaultsBackend{
+			Host:    "localhost",
+			Port:    8080,
+			Timeout: 5 * time.Second,
+			Weight:  ptr.To(1),
+		}, cfg.Primary)
+	})
+
+	t.Run("defaults func", func(t *testing.T) {
+		assert.Equal(t, 443, structs.DefaultsFor[defaultsCounter]().Port)
+		assert.Equal(t, 443, structs.DefaultsFor[*defaultsCounter]().Port)
+		assert.Equal(t, 8443, structs.Defaults(defaultsCounter{Port: 8443}).Port)
+	})
+}
+
 func TestDefaultsFor(t *testing.T) {
 	t.Run("", func(t *testing.T) {
 		cfg := structs.DefaultsFor[pg.Config]()
@@ -0,0 +1,50 @@
+package structs
+
+import (
+	"errors"
+	"fmt"
+
+	"go.chrisrx.dev/x/slices"
+	"go.chrisrx.dev/x/strings"
+)
+
+// ErrValidation is returned when the value of a field fails the `validate` tag
+// of the field.
+var ErrValidation = errors.New("failed validation")
+
+// FieldError is an error for a single field.
+type FieldError struct {
+	// Path is the path of the field from the root struct, e.g.
+	// Servers[2].Port.
+	Path string
+
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e *FieldError) Unwrap() error { return e.Err }
+
+// Errors is returned by [Validate] when one or more fields are invalid. Every
+// field is validated, so it lists all invalid fields rather than only the
+// first one.
+type Errors []*FieldError
+
+func (e Errors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "structs: %d errors:", len(e))
+	for _, err := range e {
+		sb.WriteString("\n\t")
+		sb.WriteString(err.Error())
+	}
+	return sb.String()
+}
+
+func (e Errors) Unwrap() []error {
+	return slices.Map(e, func(err *FieldError) error { return err })
+}
@@ -5,7 +5,6 @@ import (
 	"encoding"
 	"fmt"
 	"reflect"
-	"strconv"
 	"time"
 
 	// default built-in conversions
@@ -14,7 +13,6 @@ import (
 	"go.chrisrx.dev/x/convert"
 	"go.chrisrx.dev/x/expr"
 	"go.chrisrx.dev/x/internal/reflectx"
-	"go.chrisrx.dev/x/strings"
 )
 
 // Field represents a parsed struct field.
@@ -44,17 +42,39 @@ func (f Field) HasDefault() bool {
 	return f.DefaultExpr() != "" || f.Default() != ""
 }
 
-func (f Field) SetDefault(rv reflect.Value) (bool, error) {
+// SetDefault sets the default value for a field if the value is the zero
+// value. Default expressions are evaluated using the provided options, see
+// [expr.Option]. Expressions with name := value bindings are evaluated as
+// scripts, see [expr.EvalScript].
+func (f Field) SetDefault(rv reflect.Value, exprOpts ...expr.Option) (bool, error) {
+	return f.tags().setDefault(rv, exprOpts...)
+}
+
+// fieldTags are the tags of a field used to set its default value.
+type fieldTags struct {
+	def, defExpr, layout, sep string
+}
+
+func (f Field) tags() fieldTags {
+	return fieldTags{
+		def:     f.Default(),
+		defExpr: f.DefaultExpr(),
+		layout:  f.Layout(),
+		sep:     f.Separator(),
+	}
+}
+
+func (t fieldTags) setDefault(rv reflect.Value, exprOpts ...expr.Option) (bool, error) {
 	if rv.IsValid() && !rv.IsZero() {
 		return false, nil
 	}
 	opts := []convert.Option{
-		convert.Layout(f.Layout()),
-		convert.Separator(f.Separator()),
+		convert.Layout(t.layout),
+		convert.Separator(t.sep),
 	}
 	switch {
-	case f.DefaultExpr() != "":
-		v, err := expr.Eval(f.DefaultExpr())
+	case t.defExpr != "":
+		v, err := t.evalDefault(rv.Type(), exprOpts)
 		if err != nil {
 			return false, err
 		}
@@ -71,8 +91,8 @@ func (f Field) SetDefault(rv reflect.Value) (bool, error) {
 		}
 		rv.Set(v)
 		return true, nil
-	case f.Default() != "":
-		if err := ParseField(f.Default(), rv, opts...); err != nil {
+	case t.def != "":
+		if err := ParseField(t.def, rv, opts...); err != nil {
 			return false, err
 		}
 		return true, nil
@@ -81,6 +101,27 @@ func (f Field) SetDefault(rv reflect.Value) (bool, error) {
 	}
 }
 
+// evalDefault evaluates the default expression. Without options, it is only
+// compiled once and cached with the plan of the type. Options can provide
+// values with [expr.Env], which are part of the compiled program, so it is
+// compiled for each call with options.
+func (t fieldTags) evalDefault(rt reflect.Type, exprOpts []expr.Option) (reflect.Value, error) {
+	compile, eval := expr.Compile, expr.Eval
+	if expr.IsScript(t.defExpr) {
+		compile, eval = expr.CompileScript, expr.EvalScript
+	}
+	if len(exprOpts) > 0 {
+		return eval(t.defExpr, exprOpts...)
+	}
+	p, err := planFor(rt).compile(compiledKey{kind: "$default", src: t.defExpr}, func() (any, error) {
+		return compile(t.defExpr)
+	})
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return p.(*expr.Program).Run(nil)
+}
+
 func ParseFieldAs[T any](s string, opts ...convert.Option) (T, error) {
 	var zero T
 	rv := reflect.New(reflect.TypeFor[T]()).Elem()
@@ -95,124 +136,10 @@ func ParseFieldAs[T any](s string, opts ...convert.Option) (T, error) {
 }
 
 func ParseField(s string, rv reflect.Value, opts ...convert.Option) error {
-	return (&parser{
-		opts: convert.NewOptions(opts),
-	}).parse(s, rv)
-}
-
-type parser struct {
-	opts *convert.Options
-}
-
-func (p *parser) parse(s string, rv reflect.Value) error {
 	if !rv.CanSet() {
 		panic(fmt.Errorf("cannot set value: %v", rv.Type()))
 	}
-
-	// When a type-specific parser function is available, this is preferred to
-	// continuing default parsing.
-	if fn, ok := convert.Lookup(stringType, rv.Type()); ok {
-		v, err := fn(s, p.opts.Values()...)
-		if err != nil {
-			return err
-		}
-		rv.Set(reflectx.Indirect(reflect.ValueOf(v), rv.Type()))
-		return nil
-	}
-
-	// Common interfaces, like [encoding.TextUnmarshaler], can be used to set the
-	// field value.
-	if fn, ok := WellKnownInterfaces(rv); ok {
-		return fn(s)
-	}
-
-	switch rv.Kind() {
-	case reflect.Pointer:
-		if rv.IsNil() {
-			rv.Set(reflect.New(rv.Type().Elem()))
-		}
-		return p.parse(s, reflect.Indirect(rv))
-	case reflect.Array, reflect.Slice:
-		et := rv.Type().Elem()
-		if isInvalidNestedType(et) {
-			return fmt.Errorf("received invalid slice element type: %v", et)
-		}
-		elems := strings.Split(s, p.opts.Separator)
-		sv := reflect.MakeSlice(reflect.SliceOf(et), len(elems), len(elems))
-		for i := range sv.Len() {
-			if err := p.parse(elems[i], sv.Index(i)); err != nil {
-				return err
-			}
-		}
-		rv.Set(sv)
-		return nil
-	case reflect.Map:
-		elems := strings.Split(s, p.opts.Separator)
-		mv := reflect.MakeMap(rv.Type())
-		for _, elem := range elems {
-			parts := strings.SplitN(elem, "=", 2)
-			if len(parts) != 2 {
-				return fmt.Errorf("cannot parse value into key/value pairs: %q", elem)
-			}
-			key := reflect.New(rv.Type().Key()).Elem()
-			if err := p.parse(parts[0], key); err != nil {
-				return err
-			}
-			if isInvalidNestedType(key.Type()) {
-				return fmt.Errorf("received invalid map key type: %v", key.Type())
-			}
-			value := reflect.New(rv.Type().Elem()).Elem()
-			if err := p.parse(parts[1], value); err != nil {
-				return err
-			}
-			if isInvalidNestedType(value.Type()) {
-				return fmt.Errorf("received invalid map value type: %v", value.Type())
-			}
-			mv.SetMapIndex(key, value)
-		}
-		rv.Set(mv)
-		return nil
-	case reflect.String:
-		rv.SetString(s)
-		return nil
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		i, err := strconv.ParseInt(s, 0, 64)
-		if err != nil {
-			return err
-		}
-		rv.SetInt(i)
-		return nil
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		i, err := strconv.ParseUint(s, 0, 64)
-		if err != nil {
-			return err
-		}
-		rv.SetUint(i)
-		return nil
-	case reflect.Bool:
-		b, err := strconv.ParseBool(s)
-		if err != nil {
-			return err
-		}
-		rv.SetBool(b)
-		return nil
-	case reflect.Float32:
-		f, err := strconv.ParseFloat(s, 32)
-		if err != nil {
-			return err
-		}
-		rv.SetFloat(f)
-		return nil
-	case reflect.Float64:
-		f, err := strconv.ParseFloat(s, 64)
-		if err != nil {
-			return err
-		}
-		rv.SetFloat(f)
-		return nil
-	default:
-		return fmt.Errorf("received unhandled value: %T", rv.Interface())
-	}
+	return planFor(rv.Type()).parse(s, rv, convert.NewOptions(opts))
 }
 
 func isInvalidNestedType(rt reflect.Type) bool {
@@ -227,13 +154,19 @@ func isInvalidNestedType(rt reflect.Type) bool {
 var stringType = reflect.TypeFor[string]()
 
 func HasConversion(rv reflect.Value) bool {
-	_, ok := convert.Lookup(stringType, rv.Type())
-	return ok
+	return planFor(rv.Type()).conv != nil
 }
 
 type WellKnownInterfaceFunc func(s string) error
 
 func WellKnownInterfaces(rv reflect.Value) (WellKnownInterfaceFunc, bool) {
+	if !planFor(rv.Type()).wellKnown {
+		return nil, false
+	}
+	return wellKnownInterfaces(rv)
+}
+
+func wellKnownInterfaces(rv reflect.Value) (WellKnownInterfaceFunc, bool) {
 	if v := reflectx.Interface(rv); v != nil {
 		switch v := v.(type) {
 		case encoding.TextUnmarshaler:
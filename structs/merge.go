@@ -0,0 +1,209 @@
+package structs
+
+import (
+	"fmt"
+	"reflect"
+
+	"go.chrisrx.dev/x/internal/reflectx"
+	"go.chrisrx.dev/x/ptr"
+	"go.chrisrx.dev/x/set"
+)
+
+// SliceStrategy is how [Merge] merges slices.
+type SliceStrategy int
+
+const (
+	// SliceReplace replaces the slice with a non-empty slice from src.
+	SliceReplace SliceStrategy = iota
+
+	// SliceAppend appends the elements from src.
+	SliceAppend
+
+	// SliceUnion appends the elements from src that aren't already in the
+	// slice, compared the same as by [set.Set].
+	SliceUnion
+)
+
+// MapStrategy is how [Merge] merges maps.
+type MapStrategy int
+
+const (
+	// MapMerge sets the keys from src, merging values that are set for the
+	// same key in both.
+	MapMerge MapStrategy = iota
+
+	// MapReplace replaces the map with a non-empty map from src.
+	MapReplace
+)
+
+// PointerStrategy is how [Merge] merges pointers.
+type PointerStrategy int
+
+const (
+	// PointerMerge merges the value pointed to by src into a copy of the value
+	// pointed to by dst, or a new value if dst is nil. The value dst pointed to
+	// is never changed, and the pointers are never shared between dst and src.
+	PointerMerge PointerStrategy = iota
+
+	// PointerReplace replaces the pointer with a non-nil pointer from src,
+	// even if it points to a zero value.
+	PointerReplace
+)
+
+type mergeOptions struct {
+	Slices   SliceStrategy
+	Maps     MapStrategy
+	Pointers PointerStrategy
+}
+
+type MergeOption func(*mergeOptions)
+
+// WithSliceStrategy is an option for [Merge] that sets how slices are merged.
+// The default is [SliceReplace].
+func WithSliceStrategy(s SliceStrategy) MergeOption {
+	return func(o *mergeOptions) {
+		o.Slices = s
+	}
+}
+
+// WithMapStrategy is an option for [Merge] that sets how maps are merged. The
+// default is [MapMerge].
+func WithMapStrategy(s MapStrategy) MergeOption {
+	return func(o *mergeOptions) {
+		o.Maps = s
+	}
+}
+
+// WithPointerStrategy is an option for [Merge] that sets how pointers are
+// merged. The default is [PointerMerge].
+func WithPointerStrategy(s PointerStrategy) MergeOption {
+	return func(o *mergeOptions) {
+		o.Pointers = s
+	}
+}
+
+// Merge overlays the non-zero fields of src onto dst, which must be a pointer
+// to a struct of the same type as src, or the struct src points to. Nested
+// structs are merged field by field, so layered configuration can be built up
+// by merging each layer in order of precedence:
+//
+//	cfg := structs.DefaultsFor[Config]()
+//	err := structs.Merge(&cfg, fromFile)
+//
+// Fields with the `merge:"-"` tag are never merged. Whether a value is zero is
+// the same as [ptr.IsZero], so zero values in src never overwrite dst, except
+// for non-nil pointers with [PointerReplace].
+func Merge(dst, src any, opts ...MergeOption) error {
+	dv := reflect.ValueOf(dst)
+	if dv.Kind() != reflect.Pointer || dv.IsNil() || dv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("must provide a struct pointer, received %T", dst)
+	}
+	sv := reflect.ValueOf(src)
+	if sv.Kind() == reflect.Pointer && sv.Type().Elem() == dv.Elem().Type() {
+		if sv.IsNil() {
+			return nil
+		}
+		sv = sv.Elem()
+	}
+	if !sv.IsValid() || sv.Type() != dv.Elem().Type() {
+		return fmt.Errorf("cannot merge %T into %T", src, dst)
+	}
+	o := &mergeOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	o.merge(dv.Elem(), sv)
+	return nil
+}
+
+func (o *mergeOptions) merge(dst, src reflect.Value) {
+	if src.Kind() == reflect.Pointer {
+		// Pointers are replaced when they aren't nil, even if they point to a
+		// zero value.
+		if src.IsNil() {
+			return
+		}
+		if o.Pointers == PointerReplace {
+			dst.Set(src)
+			return
+		}
+		if ptr.IsZero(src.Interface()) {
+			return
+		}
+		// The value is merged into a copy, since the value dst points to may
+		// be shared with other values.
+		elem := reflect.New(dst.Type().Elem())
+		if !dst.IsNil() {
+			elem.Elem().Set(dst.Elem())
+		}
+		o.merge(elem.Elem(), src.Elem())
+		dst.Set(elem)
+		return
+	}
+	if ptr.IsZero(src.Interface()) {
+		return
+	}
+	switch {
+	case HasConversion(dst), IsWellKnown(dst):
+		// Values with a conversion, like time.Time, are merged as a whole
+		// rather than by their fields.
+		dst.Set(src)
+	case dst.Kind() == reflect.Struct:
+		for i := range dst.NumField() {
+			field := dst.Type().Field(i)
+			if !field.IsExported() || field.Tag.Get("merge") == "-" {
+				continue
+			}
+			o.merge(dst.Field(i), src.Field(i))
+		}
+	case dst.Kind() == reflect.Slice:
+		switch o.Slices {
+		case SliceAppend:
+			dst.Set(reflect.AppendSlice(cloneSlice(dst, src.Len()), src))
+		case SliceUnion:
+			out := cloneSlice(dst, src.Len())
+			seen := set.New[any]()
+			for i := range out.Len() {
+				seen.Add(out.Index(i).Interface())
+			}
+			for i := range src.Len() {
+				if elem := src.Index(i); !seen.Contains(elem.Interface()) {
+					seen.Add(elem.Interface())
+					out = reflect.Append(out, elem)
+				}
+			}
+			dst.Set(out)
+		default:
+			dst.Set(reflect.AppendSlice(reflect.MakeSlice(dst.Type(), 0, src.Len()), src))
+		}
+	case dst.Kind() == reflect.Map:
+		if o.Maps == MapReplace {
+			dst.Set(src)
+			return
+		}
+		// The map is merged into a copy, since the map of dst may be shared
+		// with other values.
+		mv := reflect.MakeMapWithSize(dst.Type(), dst.Len()+src.Len())
+		for iter := dst.MapRange(); iter.Next(); {
+			mv.SetMapIndex(iter.Key(), iter.Value())
+		}
+		for _, key := range src.MapKeys() {
+			_ = reflectx.UpdateMapIndex(mv, key, func(elem reflect.Value) error {
+				o.merge(elem, src.MapIndex(key))
+				return nil
+			})
+		}
+		dst.Set(mv)
+	default:
+		dst.Set(src)
+	}
+}
+
+// cloneSlice returns a copy of a slice with room for n more elements, so that
+// appending never changes the backing array of the slice, which may be shared
+// with other values.
+func cloneSlice(rv reflect.Value, n int) reflect.Value {
+	out := reflect.MakeSlice(rv.Type(), rv.Len(), rv.Len()+n)
+	reflect.Copy(out, rv)
+	return out
+}
@@ -0,0 +1,149 @@
+package structs_test
+
+import (
+	"testing"
+	"time"
+
+	"go.chrisrx.dev/x/assert"
+	"go.chrisrx.dev/x/ptr"
+	"go.chrisrx.dev/x/structs"
+)
+
+type mergeDatabase struct {
+	Host string
+	Port int
+}
+
+type mergeConfig struct {
+	Name     string
+	Debug    bool
+	Started  time.Time
+	Hosts    []string
+	Labels   map[string]string
+	Database mergeDatabase
+	Replica  *mergeDatabase
+	Shards   map[string]mergeDatabase
+	Version  string `merge:"-"`
+}
+
+func TestMerge(t *testing.T) {
+	base := func() mergeConfig {
+		return mergeConfig{
+			Name:     "base",
+			Hosts:    []string{"a", "b"},
+			Labels:   map[string]string{"env": "dev", "team": "core"},
+			Database: mergeDatabase{Host: "localhost", Port: 5432},
+			Shards:   map[string]mergeDatabase{"eu": {Host: "eu.local", Port: 5432}},
+			Version:  "1",
+		}
+	}
+	started := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	overlay := mergeConfig{
+		Debug:    true,
+		Started:  started,
+		Hosts:    []string{"b", "c"},
+		Labels:   map[string]string{"env": "prod"},
+		Database: mergeDatabase{Port: 6432},
+		Replica:  &mergeDatabase{Host: "replica.local"},
+		Shards:   map[string]mergeDatabase{"eu": {Port: 6432}, "us": {Host: "us.local"}},
+		Version:  "2",
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		cfg := base()
+		assert.NoError(t, structs.Merge(&cfg, overlay))
+		assert.Equal(t, mergeConfig{
+			Name:     "base",
+			Debug:    true,
+			Started:  started,
+			Hosts:    []string{"b", "c"},
+			Labels:   map[string]string{"env": "prod", "team": "core"},
+			Database: mergeDatabase{Host: "localhost", Port: 6432},
+			Replica:  &mergeDatabase{Host: "replica.local"},
+			Shards: map[string]mergeDatabase{
+				"eu": {Host: "eu.local", Port: 6432},
+				"us": {Host: "us.local"},
+			},
+			Version: "1",
+		}, cfg)
+
+		// Pointers and slices aren't shared with src.
+		assert.Equal(t, false, cfg.Replica == overlay.Replica)
+		cfg.Hosts[0] = "x"
+		assert.Equal(t, "b", overlay.Hosts[0])
+	})
+
+	t.Run("slices", func(t *testing.T) {
+		cfg := base()
+		assert.NoError(t, structs.Merge(&cfg, &overlay, structs.WithSliceStrategy(structs.SliceAppend)))
+		assert.Equal(t, []string{"a", "b", "b", "c"}, cfg.Hosts)
+
+		cfg = base()
+		assert.NoError(t, structs.Merge(&cfg, &overlay, structs.WithSliceStrategy(structs.SliceUnion)))
+		assert.Equal(t, []string{"a", "b", "c"}, cfg.Hosts)
+	})
+
+	t.Run("maps", func(t *testing.T) {
+		cfg := base()
+		assert.NoError(t, structs.Merge(&cfg, overlay, structs.WithMapStrategy(structs.MapReplace)))
+		assert.Equal(t, map[string]string{"env": "prod"}, cfg.Labels)
+		assert.Equal(t, map[string]mergeDatabase{"eu": {Port: 6432}, "us": {Host: "us.local"}}, cfg.Shards)
+	})
+
+	t.Run("pointers", func(t *testing.T) {
+		cfg := base()
+		cfg.Replica = &mergeDatabase{Port: 5433}
+		replica := cfg.Replica
+		assert.NoError(t, structs.Merge(&cfg, overlay))
+		assert.Equal(t, &mergeDatabase{Host: "replica.local", Port: 5433}, cfg.Replica)
+		// The value dst pointed to is copied rather than changed in place.
+		assert.Equal(t, false, cfg.Replica == replica)
+		assert.Equal(t, &mergeDatabase{Port: 5433}, replica)
+
+		cfg.Replica = &mergeDatabase{Port: 5433}
+		assert.NoError(t, structs.Merge(&cfg, overlay, structs.WithPointerStrategy(structs.PointerReplace)))
+		assert.Equal(t, true, cfg.Replica == overlay.Replica)
+
+		// Non-nil pointers to zero values are set.
+		empty := mergeConfig{Replica: &mergeDatabase{}}
+		cfg = base()
+		cfg.Replica = &mergeDatabase{Port: 5433}
+		assert.NoError(t, structs.Merge(&cfg, empty, structs.WithPointerStrategy(structs.PointerReplace)))
+		assert.Equal(t, true, cfg.Replica == empty.Replica)
+
+		cfg = base()
+		cfg.Replica = nil
+		assert.NoError(t, structs.Merge(&cfg, empty, structs.WithPointerStrategy(structs.PointerReplace)))
+		assert.Equal(t, true, cfg.Replica == empty.Replica)
+	})
+
+	t.Run("aliasing", func(t *testing.T) {
+		// Maps and slices of dst that are shared with another value aren't
+		// changed by merging.
+		orig := base()
+		orig.Hosts = append(make([]string, 0, 10), orig.Hosts...)
+		for _, strategy := range []structs.SliceStrategy{structs.SliceAppend, structs.SliceUnion} {
+			cfg := orig
+			assert.NoError(t, structs.Merge(&cfg, overlay, structs.WithSliceStrategy(strategy)))
+			cfg.Hosts[0] = "x"
+			assert.Equal(t, []string{"a", "b"}, orig.Hosts)
+			// Nothing was appended in the spare capacity of the slice.
+			assert.Equal(t, "", orig.Hosts[:3][2])
+		}
+		assert.Equal(t, base(), orig)
+	})
+
+	t.Run("zero values", func(t *testing.T) {
+		cfg := base()
+		assert.NoError(t, structs.Merge(&cfg, mergeConfig{Replica: ptr.To(mergeDatabase{})}))
+		assert.Equal(t, base(), cfg)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		cfg := base()
+		assert.Equal(t, "must provide a struct pointer, received structs_test.mergeConfig", structs.Merge(cfg, overlay).Error())
+		assert.Equal(t, "cannot merge structs_test.mergeDatabase into *structs_test.mergeConfig", structs.Merge(&cfg, mergeDatabase{}).Error())
+		assert.Equal(t, "cannot merge <nil> into *structs_test.mergeConfig", structs.Merge(&cfg, nil).Error())
+		assert.NoError(t, structs.Merge(&cfg, (*mergeConfig)(nil)))
+	})
+}
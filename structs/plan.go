@@ -0,0 +1,283 @@
+package structs
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"go.chrisrx.dev/x/convert"
+	"go.chrisrx.dev/x/internal/reflectx"
+	"go.chrisrx.dev/x/strings"
+	"go.chrisrx.dev/x/sync"
+)
+
+// plans caches the plan for each type, see planFor.
+var plans sync.Map
+
+// plan is what parsing values and applying defaults need to know about a
+// type. Finding conversions, interfaces and tags with reflection is slow
+// compared to using them, so a plan is only made once for each type, and
+// again after a conversion is registered.
+type plan struct {
+	version uint64
+
+	// conv is the conversion from a string to the type, if one is registered.
+	conv convert.ConversionFunc[any, any]
+
+	// wellKnown is whether values of the type might implement a well-known
+	// interface. When false, [WellKnownInterfaces] doesn't need to check.
+	wellKnown bool
+
+	// nested is whether the type is a struct that is handled by its fields,
+	// rather than a value with a conversion, like time.Time.
+	nested bool
+
+	// fields are the exported fields of a struct type.
+	fields []planField
+
+	// parse parses a string into a settable value of the type.
+	parse parseFunc
+
+	// compiled caches the expressions and rules of the tags of fields of the
+	// type, by tag, so they are only compiled once. See plan.compile.
+	compiled sync.Map
+
+	// extra caches the values of [FieldsOf] for struct types, by key.
+	extra sync.Map
+}
+
+// parseFunc parses a string into a settable value.
+type parseFunc func(s string, rv reflect.Value, opts *convert.Options) error
+
+type planField struct {
+	Field
+	index      int
+	tags       fieldTags
+	hasDefault bool
+}
+
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+// planFor returns the plan for a type, making it if it isn't cached or was
+// made before the last conversion was registered.
+func planFor(rt reflect.Type) *plan {
+	version := convert.Version()
+	if v, ok := plans.Load(rt); ok && v.(*plan).version == version {
+		return v.(*plan)
+	}
+	p := newPlan(rt, version)
+	plans.Store(rt, p)
+	return p
+}
+
+func newPlan(rt reflect.Type, version uint64) *plan {
+	p := &plan{version: version}
+	p.conv, _ = convert.Lookup(stringType, rt)
+	p.wellKnown = rt.Kind() == reflect.Interface ||
+		rt.Implements(textUnmarshalerType) ||
+		reflect.PointerTo(rt).Implements(textUnmarshalerType)
+	p.nested = rt.Kind() == reflect.Struct && p.conv == nil && !p.wellKnown
+	if rt.Kind() == reflect.Struct {
+		for i := range rt.NumField() {
+			field := Field(rt.Field(i))
+			if !field.IsExported() {
+				continue
+			}
+			p.fields = append(p.fields, planField{
+				Field:      field,
+				index:      i,
+				tags:       field.tags(),
+				hasDefault: field.HasDefault(),
+			})
+		}
+	}
+	p.parse = p.parser(rt)
+	return p
+}
+
+// compiledKey is the key of a compiled tag of a field.
+type compiledKey struct {
+	kind, name, src string
+}
+
+// compile returns the result of fn for a tag of a field of the type, calling
+// it only the first time.
+func (pl *plan) compile(key compiledKey, fn func() (any, error)) (any, error) {
+	type result struct {
+		v   any
+		err error
+	}
+	if v, ok := pl.compiled.Load(key); ok {
+		return v.(result).v, v.(result).err
+	}
+	v, err := fn()
+	r, _ := pl.compiled.LoadOrStore(key, result{v, err})
+	return r.(result).v, r.(result).err
+}
+
+// FieldsOf returns the result of fn for each field of a struct type,
+// including unexported fields, so the results can be indexed the same as the
+// fields of the type. The results are cached with the plan of the type, so fn
+// is only called once for each type and key, and again after a conversion is
+// registered. It is used by packages that read their own tags, like env, and
+// the key must be a comparable value of a type defined by the package, the
+// same as a [context.Context] key.
+func FieldsOf[T any](rt reflect.Type, key any, fn func(Field) T) []T {
+	pl := planFor(rt)
+	if v, ok := pl.extra.Load(key); ok {
+		return v.([]T)
+	}
+	fields := make([]T, rt.NumField())
+	for i := range fields {
+		fields[i] = fn(Field(rt.Field(i)))
+	}
+	v, _ := pl.extra.LoadOrStore(key, fields)
+	return v.([]T)
+}
+
+// parser returns the func used to parse values of the type.
+func (pl *plan) parser(rt reflect.Type) parseFunc {
+	switch {
+	case pl.conv != nil:
+		// When a type-specific parser function is available, this is preferred
+		// to continuing default parsing.
+		return func(s string, rv reflect.Value, opts *convert.Options) error {
+			v, err := pl.conv(s, opts.Values()...)
+			if err != nil {
+				return err
+			}
+			rv.Set(reflectx.Indirect(reflect.ValueOf(v), rv.Type()))
+			return nil
+		}
+	case pl.wellKnown:
+		// Common interfaces, like [encoding.TextUnmarshaler], can be used to set
+		// the field value. Whether they are implemented can depend on the value,
+		// e.g. for interfaces, so this is checked when parsing.
+		parse := kindParser(rt)
+		return func(s string, rv reflect.Value, opts *convert.Options) error {
+			if fn, ok := wellKnownInterfaces(rv); ok {
+				return fn(s)
+			}
+			return parse(s, rv, opts)
+		}
+	default:
+		return kindParser(rt)
+	}
+}
+
+// kindParser returns the func used to parse values of the type by its kind.
+// The plans of element types are looked up when parsing, rather than here,
+// since types can contain themselves.
+func kindParser(rt reflect.Type) parseFunc {
+	switch rt.Kind() {
+	case reflect.Pointer:
+		et := rt.Elem()
+		return func(s string, rv reflect.Value, opts *convert.Options) error {
+			if rv.IsNil() {
+				rv.Set(reflect.New(et))
+			}
+			return planFor(et).parse(s, rv.Elem(), opts)
+		}
+	case reflect.Array, reflect.Slice:
+		et := rt.Elem()
+		if isInvalidNestedType(et) {
+			return func(string, reflect.Value, *convert.Options) error {
+				return fmt.Errorf("received invalid slice element type: %v", et)
+			}
+		}
+		st := reflect.SliceOf(et)
+		return func(s string, rv reflect.Value, opts *convert.Options) error {
+			elem := planFor(et)
+			elems := strings.Split(s, opts.Separator)
+			sv := reflect.MakeSlice(st, len(elems), len(elems))
+			for i := range sv.Len() {
+				if err := elem.parse(elems[i], sv.Index(i), opts); err != nil {
+					return err
+				}
+			}
+			rv.Set(sv)
+			return nil
+		}
+	case reflect.Map:
+		kt, vt := rt.Key(), rt.Elem()
+		switch {
+		case isInvalidNestedType(kt):
+			return func(string, reflect.Value, *convert.Options) error {
+				return fmt.Errorf("received invalid map key type: %v", kt)
+			}
+		case isInvalidNestedType(vt):
+			return func(string, reflect.Value, *convert.Options) error {
+				return fmt.Errorf("received invalid map value type: %v", vt)
+			}
+		}
+		return func(s string, rv reflect.Value, opts *convert.Options) error {
+			keys, values := planFor(kt), planFor(vt)
+			elems := strings.Split(s, opts.Separator)
+			mv := reflect.MakeMapWithSize(rt, len(elems))
+			for _, elem := range elems {
+				parts := strings.SplitN(elem, "=", 2)
+				if len(parts) != 2 {
+					return fmt.Errorf("cannot parse value into key/value pairs: %q", elem)
+				}
+				key := reflect.New(kt).Elem()
+				if err := keys.parse(parts[0], key, opts); err != nil {
+					return err
+				}
+				value := reflect.New(vt).Elem()
+				if err := values.parse(parts[1], value, opts); err != nil {
+					return err
+				}
+				mv.SetMapIndex(key, value)
+			}
+			rv.Set(mv)
+			return nil
+		}
+	case reflect.String:
+		return func(s string, rv reflect.Value, opts *convert.Options) error {
+			rv.SetString(s)
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(s string, rv reflect.Value, opts *convert.Options) error {
+			i, err := strconv.ParseInt(s, 0, 64)
+			if err != nil {
+				return err
+			}
+			rv.SetInt(i)
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return func(s string, rv reflect.Value, opts *convert.Options) error {
+			i, err := strconv.ParseUint(s, 0, 64)
+			if err != nil {
+				return err
+			}
+			rv.SetUint(i)
+			return nil
+		}
+	case reflect.Bool:
+		return func(s string, rv reflect.Value, opts *convert.Options) error {
+			b, err := strconv.ParseBool(s)
+			if err != nil {
+				return err
+			}
+			rv.SetBool(b)
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		bitSize := rt.Bits()
+		return func(s string, rv reflect.Value, opts *convert.Options) error {
+			f, err := strconv.ParseFloat(s, bitSize)
+			if err != nil {
+				return err
+			}
+			rv.SetFloat(f)
+			return nil
+		}
+	default:
+		return func(s string, rv reflect.Value, opts *convert.Options) error {
+			return fmt.Errorf("received unhandled value: %T", rv.Interface())
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package structs_test
+
+import (
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.chrisrx.dev/x/assert"
+	"go.chrisrx.dev/x/convert"
+	"go.chrisrx.dev/x/env/testdata/pg"
+	"go.chrisrx.dev/x/strings"
+	"go.chrisrx.dev/x/structs"
+)
+
+type planCode string
+
+func TestParseFieldRegister(t *testing.T) {
+	code, err := structs.ParseFieldAs[planCode]("abc")
+	assert.NoError(t, err)
+	assert.Equal(t, planCode("abc"), code)
+
+	// Conversions registered after a type was parsed are used.
+	convert.Register(func(s string, _ ...convert.Option) (planCode, error) {
+		return planCode(strings.ToUpper(s)), nil
+	})
+	code, err = structs.ParseFieldAs[planCode]("abc")
+	assert.NoError(t, err)
+	assert.Equal(t, planCode("ABC"), code)
+}
+
+type planFieldsKey struct{}
+
+func TestFieldsOf(t *testing.T) {
+	type config struct {
+		Name    string `env:"NAME"`
+		private int
+		Port    int `env:"PORT"`
+	}
+	calls := 0
+	fn := func(f structs.Field) string {
+		calls++
+		return f.Tag.Get("env")
+	}
+	rt := reflect.TypeFor[config]()
+	assert.Equal(t, []string{"NAME", "", "PORT"}, structs.FieldsOf(rt, planFieldsKey{}, fn))
+	n := calls
+
+	// The results are cached, so fn isn't called again.
+	assert.Equal(t, []string{"NAME", "", "PORT"}, structs.FieldsOf(rt, planFieldsKey{}, fn))
+	assert.Equal(t, n, calls)
+}
+
+type benchValidate struct {
+	Host  string        `validate:"nonzero"`
+	Port  int           `validate:"min=1,max=65535"`
+	Name  string        `validate:"regex=^[a-z]+$"`
+	TTL   time.Duration `validate:"self % time.Minute == 0"`
+	Level string        `$default:"'info'" validate:"Level == 'debug' || Level == 'info'"`
+}
+
+func BenchmarkValidate(b *testing.B) {
+	cfg := benchValidate{Host: "localhost", Port: 8080, Name: "api", TTL: time.Hour}
+	for b.Loop() {
+		cfg.Level = ""
+		structs.Defaults(&cfg)
+		if err := structs.Validate(cfg); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+type benchConfig struct {
+	Database pg.Config
+	Backends []defaultsBackend
+	Timeout  time.Duration `default:"10s"`
+	Started  time.Time     `default:"2026-01-02T00:00:00Z"`
+	Hosts    []string      `default:"a,b,c"`
+}
+
+func BenchmarkDefaults(b *testing.B) {
+	for b.Loop() {
+		cfg := benchConfig{Backends: make([]defaultsBackend, 4)}
+		structs.Defaults(&cfg)
+	}
+}
+
+func BenchmarkParseField(b *testing.B) {
+	for name, tc := range map[string]struct {
+		s  string
+		rv reflect.Value
+	}{
+		"int":      {"42", reflect.New(reflect.TypeFor[int]()).Elem()},
+		"duration": {"5s", reflect.New(reflect.TypeFor[time.Duration]()).Elem()},
+		"ip":       {"127.0.0.1", reflect.New(reflect.TypeFor[net.IP]()).Elem()},
+		"sslmode":  {"require", reflect.New(reflect.TypeFor[pg.SSLMode]()).Elem()},
+		"slice":    {"1,2,3,4", reflect.New(reflect.TypeFor[[]int]()).Elem()},
+		"map":      {"a=1,b=2", reflect.New(reflect.TypeFor[map[string]int]()).Elem()},
+	} {
+		b.Run(name, func(b *testing.B) {
+			for b.Loop() {
+				if err := structs.ParseField(tc.s, tc.rv); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
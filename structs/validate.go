@@ -0,0 +1,250 @@
+package structs
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+
+	"go.chrisrx.dev/x/expr"
+	"go.chrisrx.dev/x/internal/reflectx"
+	"go.chrisrx.dev/x/slices"
+	"go.chrisrx.dev/x/strings"
+)
+
+// Validate checks the `validate` tags of the fields of a struct, or a struct
+// pointer, including the fields of nested structs and of structs in slices,
+// arrays and maps. A tag is either an expression that must evaluate to true,
+// with the value of the field as self or by its name, or a comma-separated
+// list of rules:
+//
+//	type Server struct {
+//		Host string `validate:"nonzero"`
+//		Port int    `validate:"min=1,max=65535"`
+//		Mode string `validate:"oneof=dev prod"`
+//		Name string `validate:"regex=^[a-z]+$"`
+//		TTL  int    `validate:"self % 60 == 0"`
+//	}
+//
+// The min and max rules compare the length of strings, slices and maps, and
+// the value of numbers. The oneof rule takes a list separated by spaces, and
+// the regex rule takes the rest of the tag, so it must be last. Expressions
+// are evaluated using the provided options, see [expr.Option].
+//
+// All invalid fields are returned as [Errors], with paths like
+// Servers[2].Port.
+func Validate(v any, exprOpts ...expr.Option) error {
+	var errs Errors
+	err := Walk(v, func(path Path, f Field, rv reflect.Value) error {
+		rule, err := f.Check(rv, exprOpts...)
+		switch {
+		case err != nil:
+			errs = append(errs, &FieldError{Path: string(path), Err: err})
+		case rule != "":
+			errs = append(errs, &FieldError{Path: string(path), Err: fmt.Errorf("%w: %s (value: %v)", ErrValidation, rule, rv)})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Check evaluates the `validate` tag of the field for a value. It returns the
+// expression or rule that the value fails, or an empty string if the value is
+// valid. See [Validate] for the syntax of the tag.
+func (f Field) Check(rv reflect.Value, exprOpts ...expr.Option) (string, error) {
+	tag := f.Tag.Get("validate")
+	if tag == "" {
+		return "", nil
+	}
+	if rules, ok := rulesFor(rv, tag); ok {
+		for _, r := range rules {
+			ok, err := r.check(rv)
+			if err != nil {
+				return "", fmt.Errorf("%s: %w", r, err)
+			}
+			if !ok {
+				return r.String(), nil
+			}
+		}
+		return "", nil
+	}
+	result, err := f.eval(rv, tag, exprOpts)
+	if err != nil {
+		return "", err
+	}
+	result = reflectx.Underlying(result)
+	if result.Kind() != reflect.Bool {
+		return "", fmt.Errorf("expected bool, received %v", result.Type())
+	}
+	if !result.Bool() {
+		return tag, nil
+	}
+	return "", nil
+}
+
+// eval evaluates a validate expression with the value as self and by the
+// name of the field. Without options, it is only compiled once for the type
+// of the value and cached with its plan, the same as default expressions.
+func (f Field) eval(rv reflect.Value, tag string, exprOpts []expr.Option) (reflect.Value, error) {
+	env := map[string]reflect.Value{
+		f.Name: rv,
+		"self": rv,
+	}
+	if len(exprOpts) > 0 {
+		return expr.New(exprOpts...).Eval(tag, expr.Env(env))
+	}
+	p, err := planFor(rv.Type()).compile(compiledKey{kind: "validate", name: f.Name, src: tag}, func() (any, error) {
+		return expr.Compile(tag, expr.Declare(f.Name, rv.Type()), expr.Declare("self", rv.Type()))
+	})
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return p.(*expr.Program).Run(env)
+}
+
+type rule struct {
+	name, arg string
+	check     func(rv reflect.Value) (bool, error)
+}
+
+func (r rule) String() string {
+	if r.arg == "" {
+		return r.name
+	}
+	return r.name + "=" + r.arg
+}
+
+// rules are the rules of a validate tag by name. Each returns the check of
+// the rule for its argument, so arguments like regular expressions are only
+// compiled once.
+var rules = map[string]func(arg string) func(rv reflect.Value) (bool, error){
+	"nonzero": func(string) func(rv reflect.Value) (bool, error) {
+		return func(rv reflect.Value) (bool, error) {
+			return rv.IsValid() && !rv.IsZero(), nil
+		}
+	},
+	"min": func(arg string) func(rv reflect.Value) (bool, error) {
+		return func(rv reflect.Value) (bool, error) {
+			n, err := compare(rv, arg)
+			return n >= 0, err
+		}
+	},
+	"max": func(arg string) func(rv reflect.Value) (bool, error) {
+		return func(rv reflect.Value) (bool, error) {
+			n, err := compare(rv, arg)
+			return n <= 0, err
+		}
+	},
+	"oneof": func(arg string) func(rv reflect.Value) (bool, error) {
+		values := strings.Fields(arg)
+		return func(rv reflect.Value) (bool, error) {
+			rv, ok := indirect(rv)
+			if !ok {
+				return true, nil
+			}
+			return slices.Contains(values, fmt.Sprint(rv)), nil
+		}
+	},
+	"regex": func(arg string) func(rv reflect.Value) (bool, error) {
+		re, err := regexp.Compile(arg)
+		return func(rv reflect.Value) (bool, error) {
+			rv, ok := indirect(rv)
+			if !ok {
+				return true, nil
+			}
+			if rv.Kind() != reflect.String {
+				return false, fmt.Errorf("not supported for %v", rv.Type())
+			}
+			if err != nil {
+				return false, err
+			}
+			return re.MatchString(rv.String()), nil
+		}
+	},
+}
+
+// rulesFor returns the parsed rules of a tag for a value, which are cached
+// with the plan of its type. See parseRules.
+func rulesFor(rv reflect.Value, tag string) ([]rule, bool) {
+	if !rv.IsValid() {
+		parsed := parseRules(tag)
+		return parsed, len(parsed) > 0
+	}
+	v, _ := planFor(rv.Type()).compile(compiledKey{kind: "rules", src: tag}, func() (any, error) {
+		return parseRules(tag), nil
+	})
+	parsed := v.([]rule)
+	return parsed, len(parsed) > 0
+}
+
+// parseRules parses a tag as a list of rules. It returns no rules if the tag
+// isn't a list of rules, so it is an expression.
+func parseRules(tag string) []rule {
+	var parsed []rule
+	for tag != "" {
+		part, rest, _ := strings.Cut(tag, ",")
+		name, arg, _ := strings.Cut(part, "=")
+		if name == "regex" {
+			_, arg, _ = strings.Cut(tag, "=")
+			rest = ""
+		}
+		newCheck, ok := rules[strings.TrimSpace(name)]
+		if !ok {
+			return nil
+		}
+		parsed = append(parsed, rule{name: strings.TrimSpace(name), arg: arg, check: newCheck(arg)})
+		tag = rest
+	}
+	return parsed
+}
+
+// compare compares a value with the argument of a min or max rule. Strings,
+// slices and maps are compared by length, and other values by parsing the
+// argument as the type of the value, e.g. "1m" for a [time.Duration].
+func compare(rv reflect.Value, arg string) (int, error) {
+	rv, ok := indirect(rv)
+	if !ok {
+		return 0, nil
+	}
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		n, err := strconv.Atoi(arg)
+		if err != nil {
+			return 0, err
+		}
+		return cmp.Compare(rv.Len(), n), nil
+	}
+	bound := reflect.New(rv.Type()).Elem()
+	if err := ParseField(arg, bound); err != nil {
+		return 0, err
+	}
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmp.Compare(rv.Int(), bound.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return cmp.Compare(rv.Uint(), bound.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return cmp.Compare(rv.Float(), bound.Float()), nil
+	default:
+		return 0, fmt.Errorf("not supported for %v", rv.Type())
+	}
+}
+
+// indirect returns the value that a pointer points to. It returns false for
+// nil pointers, which are only checked by the nonzero rule.
+func indirect(rv reflect.Value) (reflect.Value, bool) {
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return rv, false
+		}
+		rv = rv.Elem()
+	}
+	return rv, rv.IsValid()
+}
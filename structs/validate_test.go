@@ -0,0 +1,90 @@
+package structs_test
+
+import (
+	"testing"
+	"time"
+
+	"go.chrisrx.dev/x/assert"
+	"go.chrisrx.dev/x/structs"
+)
+
+type validateServer struct {
+	Host string `validate:"nonzero"`
+	Port int    `validate:"min=1,max=65535"`
+}
+
+type validateConfig struct {
+	Name    string           `validate:"regex=^[a-z]+(,[a-z]+)*$"`
+	Mode    string           `validate:"oneof=dev prod"`
+	Workers int              `validate:"self % 2 == 0"`
+	Timeout time.Duration    `validate:"max=1m"`
+	Tags    []string         `validate:"min=1"`
+	Owner   *string          `validate:"nonzero"`
+	Servers []validateServer `validate:"len(self) <= 3"`
+	Regions map[string]*validateServer
+	Backup  *validateConfig
+}
+
+func TestValidate(t *testing.T) {
+	owner := "ops"
+
+	t.Run("valid", func(t *testing.T) {
+		assert.NoError(t, structs.Validate(&validateConfig{
+			Name:    "web,api",
+			Mode:    "prod",
+			Workers: 4,
+			Timeout: 30 * time.Second,
+			Tags:    []string{"a"},
+			Owner:   &owner,
+			Servers: []validateServer{{Host: "a", Port: 80}},
+		}))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		cfg := validateConfig{
+			Name:    "Web",
+			Mode:    "test",
+			Workers: 3,
+			Timeout: 2 * time.Minute,
+			Owner:   &owner,
+			Servers: []validateServer{{Host: "a", Port: 80}, {Host: "b", Port: 80}, {Port: 0}},
+			Regions: map[string]*validateServer{
+				"us": {Host: "c", Port: 70000},
+				"eu": nil,
+			},
+			Backup: &validateConfig{Mode: "dev", Tags: []string{"a"}, Owner: &owner},
+		}
+		cfg.Backup.Backup = &cfg
+		err := structs.Validate(&cfg)
+		assert.Error(t, structs.ErrValidation, err)
+		assert.Equal(t, `structs: 9 errors:
+	Name: failed validation: regex=^[a-z]+(,[a-z]+)*$ (value: Web)
+	Mode: failed validation: oneof=dev prod (value: test)
+	Workers: failed validation: self % 2 == 0 (value: 3)
+	Timeout: failed validation: max=1m (value: 2m0s)
+	Tags: failed validation: min=1 (value: [])
+	Servers[2].Host: failed validation: nonzero (value: )
+	Servers[2].Port: failed validation: min=1 (value: 0)
+	Regions[us].Port: failed validation: max=65535 (value: 70000)
+	Backup.Name: failed validation: regex=^[a-z]+(,[a-z]+)*$ (value: )`, err.Error())
+	})
+
+	t.Run("nil pointer", func(t *testing.T) {
+		err := structs.Validate(struct {
+			Owner *string `validate:"nonzero"`
+			Port  *int    `validate:"min=1"`
+		}{})
+		assert.Equal(t, "Owner: failed validation: nonzero (value: <nil>)", err.Error())
+	})
+
+	t.Run("invalid rule", func(t *testing.T) {
+		err := structs.Validate(struct {
+			Enabled bool `validate:"min=1"`
+		}{})
+		assert.Equal(t, "Enabled: min=1: not supported for bool", err.Error())
+	})
+
+	t.Run("not a struct", func(t *testing.T) {
+		assert.Equal(t, "must provide a struct, received int", structs.Validate(1).Error())
+	})
+}
@@ -0,0 +1,286 @@
+package structs
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"go.chrisrx.dev/x/convert"
+	"go.chrisrx.dev/x/internal/reflectx"
+	"go.chrisrx.dev/x/slices"
+	"go.chrisrx.dev/x/strings"
+)
+
+// Path is the path of a field from the root struct, with the names of fields
+// separated by "." and the indexes of slices, arrays and maps in brackets,
+// e.g. Database.Pool.Max or Servers[2].Port.
+type Path string
+
+// Field returns the path of a field of the struct at p.
+func (p Path) Field(name string) Path {
+	if p == "" {
+		return Path(name)
+	}
+	return p + "." + Path(name)
+}
+
+// Index returns the path of an element of the slice, array or map at p.
+func (p Path) Index(key any) Path {
+	return p + Path(fmt.Sprintf("[%v]", key))
+}
+
+// SkipField can be returned by a [WalkFunc] to skip the fields and elements
+// within the value of a field.
+var SkipField = errors.New("skip field")
+
+// WalkFunc is called by [Walk] for each field.
+type WalkFunc func(path Path, f Field, rv reflect.Value) error
+
+// Walk calls fn for each exported field of a struct, or a struct pointer,
+// including the fields of nested structs and of structs in slices, arrays and
+// maps. Fields are walked in order, and a field is walked before the fields
+// within it. Values with a conversion, like time.Time, are walked as a whole
+// rather than by their fields.
+//
+// If the struct is walked through a pointer the values of fields are settable,
+// except for values within maps, which are copies. Walking stops at the first
+// error returned by fn, other than [SkipField], and the error is returned.
+func Walk(v any, fn WalkFunc) error {
+	rv := reflect.ValueOf(v)
+	if reflect.Indirect(rv).Kind() != reflect.Struct {
+		return fmt.Errorf("must provide a struct, received %T", v)
+	}
+	w := &walker{fn: fn, seen: make(map[uintptr]bool)}
+	return w.walk(rv, "")
+}
+
+type walker struct {
+	fn   WalkFunc
+	seen map[uintptr]bool
+}
+
+func (w *walker) walk(rv reflect.Value, path Path) error {
+	switch rv.Kind() {
+	case reflect.Pointer:
+		if rv.IsNil() || w.seen[rv.Pointer()] {
+			return nil
+		}
+		// Pointers are only followed once, since cyclic values would never
+		// finish otherwise.
+		w.seen[rv.Pointer()] = true
+		defer delete(w.seen, rv.Pointer())
+		return w.walk(rv.Elem(), path)
+	case reflect.Interface:
+		if rv.IsNil() {
+			return nil
+		}
+		return w.walk(rv.Elem(), path)
+	case reflect.Struct:
+		if HasConversion(rv) || IsWellKnown(rv) {
+			return nil
+		}
+		for _, field := range planFor(rv.Type()).fields {
+			switch err := w.fn(path.Field(field.Name), field.Field, rv.Field(field.index)); {
+			case errors.Is(err, SkipField):
+				continue
+			case err != nil:
+				return err
+			}
+			if err := w.walk(rv.Field(field.index), path.Field(field.Name)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Slice, reflect.Array:
+		for i := range rv.Len() {
+			if err := w.walk(rv.Index(i), path.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		// Keys are sorted so that fields are always walked in the same order.
+		keys := rv.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			return cmp.Compare(fmt.Sprint(a), fmt.Sprint(b))
+		})
+		for _, key := range keys {
+			if err := w.walk(rv.MapIndex(key), path.Index(key)); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return nil
+	}
+}
+
+// Get returns the value at a path within a struct, or a struct pointer, e.g.
+// Database.Pool.Max or Servers[2].Port. See [Path].
+func Get(v any, path string) (any, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	rv := reflect.ValueOf(v)
+	var p Path
+	for _, seg := range segments {
+		for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+			if rv.IsNil() {
+				return nil, fmt.Errorf("%s: nil value", cmp.Or(p, "root"))
+			}
+			rv = rv.Elem()
+		}
+		rv, p, err = seg.lookup(rv, p)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return rv.Interface(), nil
+}
+
+// Set parses s into the value at a path within the struct v points to, using
+// the same conversions as [ParseField]. Nil pointers along the path are
+// allocated, and elements of maps are added if they aren't set. The layout
+// and separator tags of the field are used, unless set by opts.
+func Set(v any, path, s string, opts ...convert.Option) error {
+	segments, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || reflect.Indirect(rv).Kind() != reflect.Struct {
+		return fmt.Errorf("must provide a struct pointer, received %T", v)
+	}
+	return setPath(rv.Elem(), segments, "", Field{}, s, opts)
+}
+
+func setPath(rv reflect.Value, segments []segment, path Path, field Field, s string, opts []convert.Option) error {
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return setPath(rv.Elem(), segments, path, field, s, opts)
+	}
+	if len(segments) == 0 {
+		opts = append([]convert.Option{
+			convert.Layout(field.Layout()),
+			convert.Separator(field.Separator()),
+		}, opts...)
+		if err := ParseField(s, rv, opts...); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		return nil
+	}
+	seg := segments[0]
+	if seg.field && rv.Kind() == reflect.Struct {
+		sf, ok := rv.Type().FieldByName(seg.name)
+		if ok {
+			field = Field(sf)
+		}
+	}
+	if !seg.field && rv.Kind() == reflect.Map {
+		key, err := seg.key(rv, path.Index(seg.name))
+		if err != nil {
+			return err
+		}
+		return reflectx.UpdateMapIndex(rv, key, func(elem reflect.Value) error {
+			return setPath(elem, segments[1:], path.Index(seg.name), field, s, opts)
+		})
+	}
+	next, path, err := seg.lookup(rv, path)
+	if err != nil {
+		return err
+	}
+	return setPath(next, segments[1:], path, field, s, opts)
+}
+
+// segment is an element of a path, either the name of a field or an index.
+type segment struct {
+	name  string
+	field bool
+}
+
+func parsePath(path string) ([]segment, error) {
+	var segments []segment
+	for s := path; s != ""; {
+		switch {
+		case strings.HasPrefix(s, "["):
+			end := strings.IndexByte(s, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid path %q: missing ]", path)
+			}
+			segments = append(segments, segment{name: s[1:end]})
+			s = s[end+1:]
+		default:
+			s = strings.TrimPrefix(s, ".")
+			end := strings.IndexAny(s, ".[")
+			if end < 0 {
+				end = len(s)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("invalid path %q: missing field name", path)
+			}
+			segments = append(segments, segment{name: s[:end], field: true})
+			s = s[end:]
+		}
+	}
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("invalid path %q", path)
+	}
+	return segments, nil
+}
+
+// lookup returns the value of the segment within rv.
+func (seg segment) lookup(rv reflect.Value, path Path) (reflect.Value, Path, error) {
+	if seg.field {
+		path = path.Field(seg.name)
+		if rv.Kind() != reflect.Struct {
+			return rv, path, fmt.Errorf("%s: cannot get field of %v", path, rv.Type())
+		}
+		sf, ok := rv.Type().FieldByName(seg.name)
+		if !ok || !sf.IsExported() {
+			return rv, path, fmt.Errorf("%s: no exported field %s in %v", path, seg.name, rv.Type())
+		}
+		v, err := rv.FieldByIndexErr(sf.Index)
+		if err != nil {
+			return rv, path, fmt.Errorf("%s: %w", path, err)
+		}
+		return v, path, nil
+	}
+	path = path.Index(seg.name)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		i, err := strconv.Atoi(seg.name)
+		if err != nil {
+			return rv, path, fmt.Errorf("%s: invalid index: %w", path, err)
+		}
+		if i < 0 || i >= rv.Len() {
+			return rv, path, fmt.Errorf("%s: index out of range with length %d", path, rv.Len())
+		}
+		return rv.Index(i), path, nil
+	case reflect.Map:
+		key, err := seg.key(rv, path)
+		if err != nil {
+			return rv, path, err
+		}
+		v := rv.MapIndex(key)
+		if !v.IsValid() {
+			return rv, path, fmt.Errorf("%s: key not found", path)
+		}
+		return v, path, nil
+	default:
+		return rv, path, fmt.Errorf("%s: cannot index %v", path, rv.Type())
+	}
+}
+
+// key parses the segment as a key of the map rv.
+func (seg segment) key(rv reflect.Value, path Path) (reflect.Value, error) {
+	key := reflect.New(rv.Type().Key()).Elem()
+	if err := ParseField(seg.name, key); err != nil {
+		return key, fmt.Errorf("%s: invalid key: %w", path, err)
+	}
+	return key, nil
+}
@@ -0,0 +1,149 @@
+package structs_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.chrisrx.dev/x/assert"
+	"go.chrisrx.dev/x/structs"
+)
+
+type walkPool struct {
+	Max     int
+	Timeout time.Duration
+}
+
+type walkServer struct {
+	Host string
+	Port int
+}
+
+type walkConfig struct {
+	Name     string
+	Started  time.Time `layout:"2006-01-02"`
+	Tags     []string  `sep:";"`
+	Database struct {
+		Pool *walkPool
+	}
+	Servers []walkServer
+	Regions map[string]walkServer
+	secret  string
+}
+
+func TestWalk(t *testing.T) {
+	cfg := walkConfig{
+		Servers: []walkServer{{Host: "a"}, {Host: "b"}},
+		Regions: map[string]walkServer{"us": {Host: "c"}, "eu": {Host: "d"}},
+	}
+	cfg.Database.Pool = &walkPool{}
+
+	t.Run("paths", func(t *testing.T) {
+		var paths []structs.Path
+		assert.NoError(t, structs.Walk(&cfg, func(path structs.Path, f structs.Field, rv reflect.Value) error {
+			paths = append(paths, path)
+			return nil
+		}))
+		assert.Equal(t, []structs.Path{
+			"Name",
+			"Started",
+			"Tags",
+			"Database",
+			"Database.Pool",
+			"Database.Pool.Max",
+			"Database.Pool.Timeout",
+			"Servers",
+			"Servers[0].Host",
+			"Servers[0].Port",
+			"Servers[1].Host",
+			"Servers[1].Port",
+			"Regions",
+			"Regions[eu].Host",
+			"Regions[eu].Port",
+			"Regions[us].Host",
+			"Regions[us].Port",
+		}, paths)
+	})
+
+	t.Run("skip and stop", func(t *testing.T) {
+		var paths []structs.Path
+		err := structs.Walk(cfg, func(path structs.Path, f structs.Field, rv reflect.Value) error {
+			paths = append(paths, path)
+			switch f.Name {
+			case "Database", "Servers":
+				return structs.SkipField
+			case "Regions":
+				return errors.New("stop")
+			}
+			return nil
+		})
+		assert.Equal(t, "stop", err.Error())
+		assert.Equal(t, []structs.Path{"Name", "Started", "Tags", "Database", "Servers", "Regions"}, paths)
+	})
+
+	t.Run("settable", func(t *testing.T) {
+		assert.NoError(t, structs.Walk(&cfg, func(path structs.Path, f structs.Field, rv reflect.Value) error {
+			if path == "Servers[1].Port" {
+				rv.SetInt(8080)
+			}
+			return nil
+		}))
+		assert.Equal(t, 8080, cfg.Servers[1].Port)
+	})
+}
+
+func TestGetSet(t *testing.T) {
+	var cfg walkConfig
+
+	assert.NoError(t, structs.Set(&cfg, "Name", "app"))
+	assert.NoError(t, structs.Set(&cfg, "Started", "2026-01-02"))
+	assert.NoError(t, structs.Set(&cfg, "Tags", "a;b"))
+	assert.NoError(t, structs.Set(&cfg, "Database.Pool.Max", "42"))
+	assert.NoError(t, structs.Set(&cfg, "Database.Pool.Timeout", "5s"))
+	assert.NoError(t, structs.Set(&cfg, "Regions[us].Port", "443"))
+
+	assert.Equal(t, "app", cfg.Name)
+	assert.Equal(t, time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), cfg.Started)
+	assert.Equal(t, []string{"a", "b"}, cfg.Tags)
+	assert.Equal(t, &walkPool{Max: 42, Timeout: 5 * time.Second}, cfg.Database.Pool)
+	assert.Equal(t, map[string]walkServer{"us": {Port: 443}}, cfg.Regions)
+
+	cfg.Servers = []walkServer{{Host: "a"}, {Host: "b"}}
+	assert.NoError(t, structs.Set(&cfg, "Servers[1].Port", "80"))
+
+	for path, expected := range map[string]any{
+		"Database.Pool.Max": 42,
+		"Servers[1]":        walkServer{Host: "b", Port: 80},
+		"Servers[1].Host":   "b",
+		"Regions[us].Port":  443,
+		"Tags[0]":           "a",
+	} {
+		v, err := structs.Get(cfg, path)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, v)
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		for path, expected := range map[string]string{
+			"Servers[2].Port":    "Servers[2]: index out of range with length 2",
+			"Servers[x]":         `Servers[x]: invalid index: strconv.Atoi: parsing "x": invalid syntax`,
+			"Regions[eu]":        "Regions[eu]: key not found",
+			"Name.Length":        "Name.Length: cannot get field of string",
+			"Missing":            "Missing: no exported field Missing in structs_test.walkConfig",
+			"secret":             "secret: no exported field secret in structs_test.walkConfig",
+			"Servers[0":          `invalid path "Servers[0": missing ]`,
+			"Database..Pool":     `invalid path "Database..Pool": missing field name`,
+			"Database.Pool.Max2": "Database.Pool.Max2: no exported field Max2 in structs_test.walkPool",
+		} {
+			_, err := structs.Get(&cfg, path)
+			assert.Equal(t, expected, err.Error())
+		}
+
+		_, err := structs.Get(walkConfig{}, "Database.Pool.Max")
+		assert.Equal(t, "Database.Pool: nil value", err.Error())
+
+		assert.Equal(t, `Database.Pool.Max: strconv.ParseInt: parsing "x": invalid syntax`, structs.Set(&cfg, "Database.Pool.Max", "x").Error())
+		assert.Equal(t, "must provide a struct pointer, received structs_test.walkConfig", structs.Set(cfg, "Name", "x").Error())
+	})
+}